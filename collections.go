@@ -0,0 +1,132 @@
+package flickgo
+
+import (
+  "errors"
+  "time"
+)
+
+// A photo set as listed in a collection.
+type CollectionSet struct {
+  ID          string `xml:"id,attr"`
+  Title       string `xml:"title,attr"`
+  Description string `xml:"description,attr"`
+}
+
+// A collection of photo sets and other collections.
+type Collection struct {
+  ID          string `xml:"id,attr"`
+  Title       string `xml:"title,attr"`
+  Description string `xml:"description,attr"`
+  IconLarge   string `xml:"iconlarge,attr"`
+  IconSmall   string `xml:"iconsmall,attr"`
+
+  // Only set by GetCollectionInfo.
+  ChildCount int           `xml:"child_count,attr"`
+  DateCreate string        `xml:"datecreate,attr"` // Unix timestamp
+  IconPhotos []SearchPhoto `xml:"iconphotos>photo"`
+
+  // Only set by GetCollectionTree.
+  Sets        []CollectionSet `xml:"set"`
+  Collections []Collection    `xml:"collection"`
+}
+
+func (c *Collection) CreateTime() time.Time {
+  return optionalTime(c.DateCreate)
+}
+
+// Returns URL for flickr.collections.getTree request.  Empty arguments are
+// left out.
+func getCollectionTreeURL(c *Client, collectionID, userID string) string {
+  args := make(map[string]string)
+  if collectionID != "" {
+    args["collection_id"] = collectionID
+  }
+  if userID != "" {
+    args["user_id"] = userID
+  }
+  return makeURL(c, "flickr.collections.getTree", args, true)
+}
+
+// Returns the tree of collections of userID, or of the calling user if
+// userID is empty.  If collectionID is not empty, only that collection and
+// its descendants are returned.
+func (c *Client) GetCollectionTree(collectionID, userID string) ([]Collection, error) {
+  r := struct {
+    Stat        string       `xml:"stat,attr"`
+    Err         flickrError  `xml:"err"`
+    Collections []Collection `xml:"collections>collection"`
+  }{}
+  u := getCollectionTreeURL(c, collectionID, userID)
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Collections, nil
+}
+
+// Returns URL for flickr.collections.getInfo request.
+func getCollectionInfoURL(c *Client, collectionID string) string {
+  args := make(map[string]string)
+  args["collection_id"] = collectionID
+  return makeURL(c, "flickr.collections.getInfo", args, true)
+}
+
+// Returns the details of a collection, without its children.
+func (c *Client) GetCollectionInfo(collectionID string) (*Collection, error) {
+  r := struct {
+    Stat       string      `xml:"stat,attr"`
+    Err        flickrError `xml:"err"`
+    Collection struct {
+      Collection
+
+      // Title and description are elements here, unlike in getTree.
+      Title       string `xml:"title"`
+      Description string `xml:"description"`
+    } `xml:"collection"`
+  }{}
+  if err := flickrGet(c, getCollectionInfoURL(c, collectionID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  coll := r.Collection.Collection
+  coll.Title = r.Collection.Title
+  coll.Description = r.Collection.Description
+  return &coll, nil
+}
+
+// Returned by a CollectionWalkFunc to skip the children of the current collection.
+var SkipCollection = errors.New("skip this collection")
+
+// Called by WalkCollections for each collection.  path holds the ancestors
+// of coll, outermost first.
+type CollectionWalkFunc func(path []*Collection, coll *Collection) error
+
+// Walks collection trees depth-first, calling fn for each collection before
+// its children.  If fn returns SkipCollection, the children of that
+// collection are skipped; any other error stops the walk and is returned.
+func WalkCollections(colls []Collection, fn CollectionWalkFunc) error {
+  return walkCollections(nil, colls, fn)
+}
+
+func walkCollections(path []*Collection, colls []Collection, fn CollectionWalkFunc) error {
+  for i := range colls {
+    coll := &colls[i]
+    err := fn(path, coll)
+    if err == SkipCollection {
+      continue
+    }
+    if err != nil {
+      return err
+    }
+    // Copy path so that fn may keep it.
+    childPath := append(path[:len(path):len(path)], coll)
+    if err := walkCollections(childPath, coll.Collections, fn); err != nil {
+      return err
+    }
+  }
+  return nil
+}
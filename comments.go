@@ -0,0 +1,157 @@
+package flickgo
+
+import (
+  "strconv"
+  "time"
+)
+
+// A comment on a photo or photo set.
+type Comment struct {
+  ID string `xml:"id,attr"`
+
+  // NSID and names of the comment's author.
+  Author     string `xml:"author,attr"`
+  AuthorName string `xml:"authorname,attr"`
+  RealName   string `xml:"realname,attr"`
+
+  DateCreate string `xml:"datecreate,attr"` // Unix timestamp
+  Permalink  string `xml:"permalink,attr"`
+
+  // Comment text, which may contain HTML.
+  HTML string `xml:",chardata"`
+}
+
+func (c *Comment) CreateTime() time.Time {
+  return optionalTime(c.DateCreate)
+}
+
+// Returns URL for flickr.photos.comments.getList request.  Zero dates are
+// left out.
+func getCommentsURL(c *Client, photoID string, minDate, maxDate time.Time) string {
+  args := make(map[string]string)
+  args["photo_id"] = photoID
+  if !minDate.IsZero() {
+    args["min_comment_date"] = strconv.FormatInt(minDate.Unix(), 10)
+  }
+  if !maxDate.IsZero() {
+    args["max_comment_date"] = strconv.FormatInt(maxDate.Unix(), 10)
+  }
+  return makeURL(c, "flickr.photos.comments.getList", args, true)
+}
+
+// Returns the comments on a photo, oldest first.  Only comments made between
+// minDate and maxDate are returned; zero dates are not limits.
+func (c *Client) GetComments(photoID string, minDate, maxDate time.Time) ([]Comment, error) {
+  return getCommentList(c, getCommentsURL(c, photoID, minDate, maxDate))
+}
+
+// Returns URL for flickr.photosets.comments.getList request.
+func getSetCommentsURL(c *Client, setID string) string {
+  args := make(map[string]string)
+  args["photoset_id"] = setID
+  return makeURL(c, "flickr.photosets.comments.getList", args, true)
+}
+
+// Returns the comments on a photo set, oldest first.
+func (c *Client) GetSetComments(setID string) ([]Comment, error) {
+  return getCommentList(c, getSetCommentsURL(c, setID))
+}
+
+// Sends a comments.getList request.
+func getCommentList(c *Client, u string) ([]Comment, error) {
+  r := struct {
+    Stat     string      `xml:"stat,attr"`
+    Err      flickrError `xml:"err"`
+    Comments []Comment   `xml:"comments>comment"`
+  }{}
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Comments, nil
+}
+
+// Sends an addComment request and returns the ID of the new comment.
+func addComment(c *Client, method string, args map[string]string) (string, error) {
+  req, err := postRequest(c, method, args)
+  if err != nil {
+    return "", err
+  }
+  r := struct {
+    Stat    string      `xml:"stat,attr"`
+    Err     flickrError `xml:"err"`
+    Comment struct {
+      ID string `xml:"id,attr"`
+    } `xml:"comment"`
+  }{}
+  if err := flickrPost(c, req, &r); err != nil {
+    return "", err
+  }
+  if r.Stat != "ok" {
+    return "", r.Err.Err()
+  }
+  return r.Comment.ID, nil
+}
+
+// Adds a comment to a photo and returns the comment's ID.
+func (c *Client) AddComment(photoID, text string) (string, error) {
+  args := map[string]string{
+    "photo_id":     photoID,
+    "comment_text": text,
+  }
+  return addComment(c, "flickr.photos.comments.addComment", args)
+}
+
+// Changes the text of a comment on a photo.
+func (c *Client) EditComment(commentID, text string) error {
+  args := map[string]string{
+    "comment_id":   commentID,
+    "comment_text": text,
+  }
+  return postOK(c, "flickr.photos.comments.editComment", args)
+}
+
+// Deletes a comment on a photo.
+func (c *Client) DeleteComment(commentID string) error {
+  return postOK(c, "flickr.photos.comments.deleteComment",
+    map[string]string{"comment_id": commentID})
+}
+
+// Adds a comment to a photo set and returns the comment's ID.
+func (c *Client) AddSetComment(setID, text string) (string, error) {
+  args := map[string]string{
+    "photoset_id":  setID,
+    "comment_text": text,
+  }
+  return addComment(c, "flickr.photosets.comments.addComment", args)
+}
+
+// Changes the text of a comment on a photo set.
+func (c *Client) EditSetComment(commentID, text string) error {
+  args := map[string]string{
+    "comment_id":   commentID,
+    "comment_text": text,
+  }
+  return postOK(c, "flickr.photosets.comments.editComment", args)
+}
+
+// Deletes a comment on a photo set.
+func (c *Client) DeleteSetComment(commentID string) error {
+  return postOK(c, "flickr.photosets.comments.deleteComment",
+    map[string]string{"comment_id": commentID})
+}
+
+// Returns a page of the calling user's contacts' photos that were recently
+// commented on.  args may contain date_lastcomment, contacts_filter, extras,
+// page and per_page.
+func (c *Client) GetRecentCommentsForContacts(args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.photos.comments.getRecentForContacts", args)
+}
+
+// Returns an iterator over all of the calling user's contacts' photos that
+// were recently commented on.
+func (c *Client) GetRecentCommentsForContactsAll(args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.photos.comments.getRecentForContacts", args)
+}
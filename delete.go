@@ -0,0 +1,81 @@
+package flickgo
+
+import (
+  "errors"
+  "time"
+)
+
+// Returned by BulkDelete when the confirmation callback declines.
+var ErrDeleteNotConfirmed = errors.New("deletion not confirmed")
+
+// Deletes a photo.  Requires an auth token with DeletePerm.
+func (c *Client) DeletePhoto(photoID string) error {
+  return postOK(c, "flickr.photos.delete", map[string]string{"photo_id": photoID})
+}
+
+// Options for BulkDelete.
+type DeleteOptions struct {
+  // Report what would be deleted without deleting anything.
+  DryRun bool
+
+  // Called once with all photo IDs before anything is deleted; nothing is
+  // deleted unless it returns true.  Required unless DryRun is set.
+  Confirm func(photoIDs []string) bool
+
+  // Minimum time between two delete requests.
+  Interval time.Duration
+}
+
+// Outcome of deleting a single photo.
+type DeleteResult struct {
+  PhotoID string
+
+  // False for dry runs and failures.
+  Deleted bool
+
+  Err error
+}
+
+// Deletes photos one by one, reporting the outcome for each.  Failure to
+// delete one photo doesn't stop the others.  Returns ErrDeleteNotConfirmed,
+// and deletes nothing, if opts.Confirm is missing or returns false.
+func (c *Client) BulkDelete(photoIDs []string, opts DeleteOptions) ([]DeleteResult, error) {
+  results := make([]DeleteResult, len(photoIDs))
+  for i, id := range photoIDs {
+    results[i].PhotoID = id
+  }
+  if opts.DryRun {
+    return results, nil
+  }
+  if opts.Confirm == nil || !opts.Confirm(photoIDs) {
+    return results, ErrDeleteNotConfirmed
+  }
+
+  var last time.Time
+  for i := range results {
+    if wait := opts.Interval - time.Since(last); !last.IsZero() && wait > 0 {
+      time.Sleep(wait)
+    }
+    last = time.Now()
+    if err := c.DeletePhoto(results[i].PhotoID); err != nil {
+      results[i].Err = err
+    } else {
+      results[i].Deleted = true
+    }
+  }
+  return results, nil
+}
+
+// Deletes all photos returned by it; see BulkDelete.  All photos are listed
+// before any is deleted, so that deletion doesn't shift the pages being
+// iterated.
+func (c *Client) BulkDeleteIter(it *PhotoIterator, opts DeleteOptions) ([]DeleteResult, error) {
+  var ids []string
+  for it.Next() {
+    ids = append(ids, it.Photo().ID)
+  }
+  if err := it.Err(); err != nil {
+    return nil, wrapErr("listing photos failed", err)
+  }
+  return c.BulkDelete(ids, opts)
+}
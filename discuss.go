@@ -0,0 +1,249 @@
+package flickgo
+
+import (
+  "time"
+)
+
+// A discussion topic in a group.
+type Topic struct {
+  ID      string `xml:"id,attr"`
+  Subject string `xml:"subject,attr"`
+
+  // Only set by GetTopicInfo and GetReplies.
+  GroupID string `xml:"group_id,attr"`
+
+  // NSID and name of the topic's author, and their role in the group:
+  // "member", "moderator" or "admin".
+  Author     string `xml:"author,attr"`
+  AuthorName string `xml:"authorname,attr"`
+  Role       string `xml:"role,attr"`
+
+  // Text of the first post, which may contain HTML.
+  Message string `xml:"message"`
+
+  Replies      int    `xml:"count_replies,attr"`
+  DateCreate   string `xml:"datecreate,attr"`   // Unix timestamp
+  DateLastPost string `xml:"datelastpost,attr"` // Unix timestamp
+
+  IsPinned bool `xml:"is_pinned,attr"`
+  IsLocked bool `xml:"is_locked,attr"`
+
+  // What the calling user may do with the topic.
+  CanEdit   bool `xml:"can_edit,attr"`
+  CanDelete bool `xml:"can_delete,attr"`
+  CanReply  bool `xml:"can_reply,attr"`
+}
+
+func (t *Topic) CreateTime() time.Time {
+  return optionalTime(t.DateCreate)
+}
+
+func (t *Topic) LastPostTime() time.Time {
+  return optionalTime(t.DateLastPost)
+}
+
+// A reply to a discussion topic.
+type Reply struct {
+  ID         string `xml:"id,attr"`
+  Author     string `xml:"author,attr"`
+  AuthorName string `xml:"authorname,attr"`
+  Role       string `xml:"role,attr"`
+
+  // Text of the reply, which may contain HTML.
+  Message string `xml:"message"`
+
+  DateCreate string `xml:"datecreate,attr"` // Unix timestamp
+  LastEdit   string `xml:"lastedit,attr"`   // Unix timestamp
+
+  CanEdit   bool `xml:"can_edit,attr"`
+  CanDelete bool `xml:"can_delete,attr"`
+}
+
+func (r *Reply) CreateTime() time.Time {
+  return optionalTime(r.DateCreate)
+}
+
+// Returns the zero time if the reply was never edited.
+func (r *Reply) LastEditTime() time.Time {
+  return optionalTime(r.LastEdit)
+}
+
+// Response for flickr.groups.discuss.topics.getList requests.
+type TopicsResponse struct {
+  Paging
+
+  GroupID string  `xml:"group_id,attr"`
+  Name    string  `xml:"name,attr"`
+  Topics  []Topic `xml:"topic"`
+}
+
+// Response for flickr.groups.discuss.replies.getList requests.
+type RepliesResponse struct {
+  Paging
+
+  Topic   Topic
+  Replies []Reply
+}
+
+// Returns URL for flickr.groups.discuss.topics.getList request.
+func getTopicsURL(c *Client, groupID string, page, perPage int) string {
+  args := make(map[string]string)
+  args["group_id"] = groupID
+  setPage(args, page, perPage)
+  return makeURL(c, "flickr.groups.discuss.topics.getList", args, true)
+}
+
+// Returns a page of the discussion topics of a group, most recently active
+// first.  page and perPage are ignored when zero.
+func (c *Client) GetTopics(groupID string, page, perPage int) (*TopicsResponse, error) {
+  r := struct {
+    Stat     string      `xml:"stat,attr"`
+    Err      flickrError `xml:"err"`
+    Response struct {
+      TopicsResponse
+
+      // Topics spell perpage differently than other lists.
+      PerPage int `xml:"per_page,attr"`
+    } `xml:"topics"`
+  }{}
+  if err := flickrGet(c, getTopicsURL(c, groupID, page, perPage), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  resp := &r.Response.TopicsResponse
+  resp.PerPage = r.Response.PerPage
+  return resp, nil
+}
+
+// Returns URL for flickr.groups.discuss.topics.getInfo request.
+func getTopicInfoURL(c *Client, topicID string) string {
+  args := make(map[string]string)
+  args["topic_id"] = topicID
+  return makeURL(c, "flickr.groups.discuss.topics.getInfo", args, true)
+}
+
+// Returns a discussion topic.
+func (c *Client) GetTopicInfo(topicID string) (*Topic, error) {
+  r := struct {
+    Stat  string      `xml:"stat,attr"`
+    Err   flickrError `xml:"err"`
+    Topic Topic       `xml:"topic"`
+  }{}
+  if err := flickrGet(c, getTopicInfoURL(c, topicID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Topic, nil
+}
+
+// Starts a discussion topic in a group.
+func (c *Client) AddTopic(groupID, subject, message string) error {
+  args := map[string]string{
+    "group_id": groupID,
+    "subject":  subject,
+    "message":  message,
+  }
+  return postOK(c, "flickr.groups.discuss.topics.add", args)
+}
+
+// Returns URL for flickr.groups.discuss.replies.getList request.
+func getRepliesURL(c *Client, topicID string, page, perPage int) string {
+  args := make(map[string]string)
+  args["topic_id"] = topicID
+  setPage(args, page, perPage)
+  return makeURL(c, "flickr.groups.discuss.replies.getList", args, true)
+}
+
+// Returns a discussion topic and a page of its replies, oldest first.  page
+// and perPage are ignored when zero.
+func (c *Client) GetReplies(topicID string, page, perPage int) (*RepliesResponse, error) {
+  r := struct {
+    Stat    string      `xml:"stat,attr"`
+    Err     flickrError `xml:"err"`
+    Replies struct {
+      // The paging attributes are on the topic, and per_page and the topic
+      // ID are spelled differently than elsewhere.
+      Topic struct {
+        Topic
+
+        TopicID string `xml:"topic_id,attr"`
+        Page    int    `xml:"page,attr"`
+        Pages   int    `xml:"pages,attr"`
+        PerPage int    `xml:"per_page,attr"`
+        Total   int    `xml:"total,attr"`
+      } `xml:"topic"`
+      Replies []Reply `xml:"reply"`
+    } `xml:"replies"`
+  }{}
+  if err := flickrGet(c, getRepliesURL(c, topicID, page, perPage), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  t := &r.Replies.Topic
+  if t.ID == "" {
+    t.ID = t.TopicID
+  }
+  return &RepliesResponse{
+    Paging:  Paging{Page: t.Page, Pages: t.Pages, PerPage: t.PerPage, Total: t.Total},
+    Topic:   t.Topic,
+    Replies: r.Replies.Replies,
+  }, nil
+}
+
+// Returns URL for flickr.groups.discuss.replies.getInfo request.
+func getReplyInfoURL(c *Client, topicID, replyID string) string {
+  args := make(map[string]string)
+  args["topic_id"] = topicID
+  args["reply_id"] = replyID
+  return makeURL(c, "flickr.groups.discuss.replies.getInfo", args, true)
+}
+
+// Returns a reply to a discussion topic.
+func (c *Client) GetReplyInfo(topicID, replyID string) (*Reply, error) {
+  r := struct {
+    Stat  string      `xml:"stat,attr"`
+    Err   flickrError `xml:"err"`
+    Reply Reply       `xml:"reply"`
+  }{}
+  if err := flickrGet(c, getReplyInfoURL(c, topicID, replyID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Reply, nil
+}
+
+// Posts a reply to a discussion topic.
+func (c *Client) AddReply(topicID, message string) error {
+  args := map[string]string{
+    "topic_id": topicID,
+    "message":  message,
+  }
+  return postOK(c, "flickr.groups.discuss.replies.add", args)
+}
+
+// Changes the text of a reply.
+func (c *Client) EditReply(topicID, replyID, message string) error {
+  args := map[string]string{
+    "topic_id": topicID,
+    "reply_id": replyID,
+    "message":  message,
+  }
+  return postOK(c, "flickr.groups.discuss.replies.edit", args)
+}
+
+// Deletes a reply.
+func (c *Client) DeleteReply(topicID, replyID string) error {
+  args := map[string]string{
+    "topic_id": topicID,
+    "reply_id": replyID,
+  }
+  return postOK(c, "flickr.groups.discuss.replies.delete", args)
+}
@@ -0,0 +1,221 @@
+package flickgo
+
+import (
+  "regexp"
+  "strconv"
+  "strings"
+)
+
+// One EXIF, TIFF, GPS or other metadata tag of a photo.
+type ExifTag struct {
+  TagSpace   string `xml:"tagspace,attr"`
+  TagSpaceID string `xml:"tagspaceid,attr"`
+  Tag        string `xml:"tag,attr"`
+  Label      string `xml:"label,attr"`
+
+  // Value as stored in the file.
+  Raw string `xml:"raw"`
+  // Formatted value, when Flickr has one.
+  Clean string `xml:"clean"`
+}
+
+// Returns the formatted value of the tag if there is one, the raw value
+// otherwise.
+func (t *ExifTag) Value() string {
+  if t.Clean != "" {
+    return t.Clean
+  }
+  return t.Raw
+}
+
+// Response for flickr.photos.getExif requests.
+type ExifResponse struct {
+  Photo
+
+  // Camera name, like "Canon EOS 5D".
+  Camera string    `xml:"camera,attr"`
+  Tags   []ExifTag `xml:"exif"`
+}
+
+// Returns the first tag named tag in any tag space, or nil.
+func (r *ExifResponse) Find(tag string) *ExifTag {
+  for i := range r.Tags {
+    if r.Tags[i].Tag == tag {
+      return &r.Tags[i]
+    }
+  }
+  return nil
+}
+
+// Returns the value of the first of tags present, or "".
+func (r *ExifResponse) value(tags ...string) string {
+  for _, tag := range tags {
+    if t := r.Find(tag); t != nil {
+      return t.Value()
+    }
+  }
+  return ""
+}
+
+// Returns the camera maker.
+func (r *ExifResponse) Make() string {
+  return r.value("Make")
+}
+
+// Returns the camera model.
+func (r *ExifResponse) Model() string {
+  return r.value("Model")
+}
+
+// Returns the lens description.
+func (r *ExifResponse) Lens() string {
+  return r.value("LensModel", "Lens", "LensInfo")
+}
+
+// Returns the exposure time, like "0.017 sec (1/60)".
+func (r *ExifResponse) Exposure() string {
+  return r.value("ExposureTime")
+}
+
+// Returns the aperture, like "f/5.6".
+func (r *ExifResponse) Aperture() string {
+  return r.value("FNumber", "ApertureValue")
+}
+
+// Returns the ISO speed, like "400".
+func (r *ExifResponse) ISO() string {
+  return r.value("ISO", "ISOSpeedRatings")
+}
+
+// Returns the focal length, like "50 mm".
+func (r *ExifResponse) FocalLength() string {
+  return r.value("FocalLength")
+}
+
+// Returns the position the photo was taken at, in decimal degrees, from the
+// GPS tags.  ok is false if the photo has no usable GPS tags.
+func (r *ExifResponse) GPS() (lat, lon float64, ok bool) {
+  lat, latOK := gpsCoord(r.Find("GPSLatitude"), r.Find("GPSLatitudeRef"))
+  lon, lonOK := gpsCoord(r.Find("GPSLongitude"), r.Find("GPSLongitudeRef"))
+  if !latOK || !lonOK {
+    return 0, 0, false
+  }
+  return lat, lon, true
+}
+
+var gpsNumber = regexp.MustCompile(`[0-9]+(\.[0-9]*)?`)
+
+// Converts a GPS coordinate like `51 deg 30' 26.46"` and its reference, like
+// "North" or "W", to decimal degrees.
+func gpsCoord(v, ref *ExifTag) (float64, bool) {
+  if v == nil {
+    return 0, false
+  }
+  nums := gpsNumber.FindAllString(v.Raw, 3)
+  if len(nums) == 0 {
+    return 0, false
+  }
+  deg := 0.0
+  for i, n := range nums {
+    f, err := strconv.ParseFloat(n, 64)
+    if err != nil {
+      return 0, false
+    }
+    deg += f / []float64{1, 60, 3600}[i]
+  }
+  hemisphere := strings.TrimSpace(v.Raw)
+  if ref != nil {
+    hemisphere = ref.Raw
+  }
+  hemisphere = strings.ToUpper(strings.TrimSpace(hemisphere))
+  if strings.HasPrefix(hemisphere, "-") || strings.HasPrefix(hemisphere, "S") ||
+    strings.HasPrefix(hemisphere, "W") || strings.HasSuffix(hemisphere, "S") ||
+    strings.HasSuffix(hemisphere, "W") {
+    deg = -deg
+  }
+  return deg, true
+}
+
+// Returns URL for flickr.photos.getExif request.
+func getExifURL(c *Client, photoID, secret string) string {
+  args := make(map[string]string)
+  args["photo_id"] = photoID
+  if secret != "" {
+    args["secret"] = secret
+  }
+  return makeURL(c, "flickr.photos.getExif", args, true)
+}
+
+// Returns the EXIF and other metadata of a photo.  secret lets callers read
+// the metadata of photos they can't otherwise see; it may be empty.
+func (c *Client) GetExif(photoID, secret string) (*ExifResponse, error) {
+  r := struct {
+    Stat  string       `xml:"stat,attr"`
+    Err   flickrError  `xml:"err"`
+    Photo ExifResponse `xml:"photo"`
+  }{}
+  if err := flickrGet(c, getExifURL(c, photoID, secret), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Photo, nil
+}
+
+// A camera maker.
+type CameraBrand struct {
+  ID   string `xml:"id,attr"`
+  Name string `xml:"name,attr"`
+}
+
+// A camera model.
+type Camera struct {
+  ID         string  `xml:"id,attr"`
+  Name       string  `xml:"name"`
+  MegaPixels float64 `xml:"details>megapixels"`
+  LCDSize    float64 `xml:"details>lcd_screen_size"`
+  MemoryType string  `xml:"details>memory_type"`
+  SmallImage string  `xml:"images>small"`
+  LargeImage string  `xml:"images>large"`
+}
+
+// Returns all camera brands known to Flickr.
+func (c *Client) GetCameraBrands() ([]CameraBrand, error) {
+  r := struct {
+    Stat   string        `xml:"stat,attr"`
+    Err    flickrError   `xml:"err"`
+    Brands []CameraBrand `xml:"brands>brand"`
+  }{}
+  u := makeURL(c, "flickr.cameras.getBrands", map[string]string{}, true)
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Brands, nil
+}
+
+// Returns URL for flickr.cameras.getBrandModels request.
+func getBrandModelsURL(c *Client, brandID string) string {
+  args := make(map[string]string)
+  args["brand"] = brandID
+  return makeURL(c, "flickr.cameras.getBrandModels", args, true)
+}
+
+// Returns the camera models of a brand, given its ID.
+func (c *Client) GetBrandModels(brandID string) ([]Camera, error) {
+  r := struct {
+    Stat    string      `xml:"stat,attr"`
+    Err     flickrError `xml:"err"`
+    Cameras []Camera    `xml:"cameras>camera"`
+  }{}
+  if err := flickrGet(c, getBrandModelsURL(c, brandID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Cameras, nil
+}
@@ -0,0 +1,225 @@
+package flickgo
+
+import (
+  "bufio"
+  "encoding/json"
+  "encoding/xml"
+  "fmt"
+  "html"
+  "io"
+)
+
+// Streams geotagged photos to a map file.  Photos without a location are
+// skipped.  Close must be called to finish the file.
+type PhotoWriter interface {
+  Write(p *SearchPhoto) error
+  Close() error
+}
+
+// Writes all photos returned by it to pw, then closes pw, also if writing or
+// listing the photos fails.  Returns the number of photos written.  The photos
+// must be listed with the geo extra, or none of them will have a location.
+func ExportPhotos(pw PhotoWriter, it *PhotoIterator) (n int, err error) {
+  defer func() {
+    if cErr := pw.Close(); err == nil {
+      err = cErr
+    }
+  }()
+  for it.Next() {
+    p := it.Photo()
+    if !p.HasLocation() {
+      continue
+    }
+    if err := pw.Write(&p); err != nil {
+      return n, err
+    }
+    n++
+  }
+  if err := it.Err(); err != nil {
+    return n, wrapErr("listing photos failed", err)
+  }
+  return n, nil
+}
+
+// Returns the title of p, or its ID if it has none.
+func exportTitle(p *SearchPhoto) string {
+  if p.Title == "" {
+    return p.ID
+  }
+  return p.Title
+}
+
+// Writes photos as a GeoJSON FeatureCollection of points.  Each feature has
+// the photo ID as id and title, url, thumbnail and owner properties.
+type GeoJSONWriter struct {
+  // Size of the thumbnail URLs, like SizeThumbnail.
+  ThumbSize string
+
+  w      *bufio.Writer
+  n      int
+  closed bool
+}
+
+// Creates a GeoJSONWriter writing to w, with SizeThumbnail thumbnails.
+func NewGeoJSONWriter(w io.Writer) *GeoJSONWriter {
+  return &GeoJSONWriter{ThumbSize: SizeThumbnail, w: bufio.NewWriter(w)}
+}
+
+type geoJSONFeature struct {
+  Type       string            `json:"type"`
+  ID         string            `json:"id"`
+  Geometry   geoJSONPoint      `json:"geometry"`
+  Properties map[string]string `json:"properties"`
+}
+
+type geoJSONPoint struct {
+  Type        string     `json:"type"`
+  Coordinates [2]float64 `json:"coordinates"`
+}
+
+// Writes p as a feature, unless it has no location.
+func (g *GeoJSONWriter) Write(p *SearchPhoto) error {
+  if !p.HasLocation() {
+    return nil
+  }
+  f := geoJSONFeature{
+    Type: "Feature",
+    ID:   p.ID,
+    Geometry: geoJSONPoint{
+      Type:        "Point",
+      Coordinates: [2]float64{p.Longitude, p.Latitude},
+    },
+    Properties: map[string]string{
+      "title":     exportTitle(p),
+      "url":       p.PageURL(),
+      "thumbnail": p.URL(g.ThumbSize),
+      "owner":     p.Owner,
+    },
+  }
+  data, err := json.Marshal(f)
+  if err != nil {
+    return wrapErr("encoding GeoJSON failed", err)
+  }
+  sep := ",\n"
+  if g.n == 0 {
+    sep = `{"type":"FeatureCollection","features":[` + "\n"
+  }
+  g.n++
+  if _, err := g.w.WriteString(sep); err != nil {
+    return wrapErr("writing GeoJSON failed", err)
+  }
+  if _, err := g.w.Write(data); err != nil {
+    return wrapErr("writing GeoJSON failed", err)
+  }
+  return nil
+}
+
+// Ends the FeatureCollection and flushes it.
+func (g *GeoJSONWriter) Close() error {
+  if g.closed {
+    return nil
+  }
+  g.closed = true
+  end := "\n]}\n"
+  if g.n == 0 {
+    end = `{"type":"FeatureCollection","features":[]}` + "\n"
+  }
+  if _, err := g.w.WriteString(end); err != nil {
+    return wrapErr("writing GeoJSON failed", err)
+  }
+  if err := g.w.Flush(); err != nil {
+    return wrapErr("writing GeoJSON failed", err)
+  }
+  return nil
+}
+
+// Writes photos as a KML document of placemarks.  Each placemark shows the
+// photo's title and a thumbnail linking to the photo page.
+type KMLWriter struct {
+  // Size of the thumbnail URLs, like SizeThumbnail.
+  ThumbSize string
+
+  name    string
+  w       *bufio.Writer
+  enc     *xml.Encoder
+  started bool
+  closed  bool
+}
+
+// Creates a KMLWriter writing a document called name to w, with
+// SizeThumbnail thumbnails.
+func NewKMLWriter(w io.Writer, name string) *KMLWriter {
+  bw := bufio.NewWriter(w)
+  return &KMLWriter{
+    ThumbSize: SizeThumbnail,
+    name:      name,
+    w:         bw,
+    enc:       xml.NewEncoder(bw),
+  }
+}
+
+type kmlPlacemark struct {
+  XMLName     xml.Name `xml:"Placemark"`
+  ID          string   `xml:"id,attr"`
+  Name        string   `xml:"name"`
+  Description string   `xml:"description"`
+  Coordinates string   `xml:"Point>coordinates"`
+}
+
+// Writes the start of the document.
+func (k *KMLWriter) start() error {
+  if k.started {
+    return nil
+  }
+  k.started = true
+  if _, err := k.w.WriteString(xml.Header +
+    `<kml xmlns="http://www.opengis.net/kml/2.2"><Document>`); err != nil {
+    return err
+  }
+  return k.enc.Encode(struct {
+    XMLName xml.Name `xml:"name"`
+    Name    string   `xml:",chardata"`
+  }{Name: k.name})
+}
+
+// Writes p as a placemark, unless it has no location.
+func (k *KMLWriter) Write(p *SearchPhoto) error {
+  if !p.HasLocation() {
+    return nil
+  }
+  if err := k.start(); err != nil {
+    return wrapErr("writing KML failed", err)
+  }
+  title := exportTitle(p)
+  desc := fmt.Sprintf(`<a href="%s"><img src="%s" alt="%s"/></a>`,
+    html.EscapeString(p.PageURL()), html.EscapeString(p.URL(k.ThumbSize)),
+    html.EscapeString(title))
+  pm := kmlPlacemark{
+    ID:          "photo" + p.ID,
+    Name:        title,
+    Description: desc,
+    Coordinates: formatDegrees(p.Longitude) + "," + formatDegrees(p.Latitude),
+  }
+  if err := k.enc.Encode(pm); err != nil {
+    return wrapErr("writing KML failed", err)
+  }
+  return nil
+}
+
+// Ends the document and flushes it.
+func (k *KMLWriter) Close() error {
+  if k.closed {
+    return nil
+  }
+  k.closed = true
+  if err := k.start(); err != nil {
+    return wrapErr("writing KML failed", err)
+  }
+  if _, err := k.w.WriteString("</Document></kml>\n"); err != nil {
+    return wrapErr("writing KML failed", err)
+  }
+  if err := k.w.Flush(); err != nil {
+    return wrapErr("writing KML failed", err)
+  }
+  return nil
+}
@@ -0,0 +1,117 @@
+package flickgo
+
+import (
+  "time"
+)
+
+// Adds a photo to the calling user's favorites.
+func (c *Client) AddFavorite(photoID string) error {
+  return postOK(c, "flickr.favorites.add", map[string]string{"photo_id": photoID})
+}
+
+// Removes a photo from the calling user's favorites.
+func (c *Client) RemoveFavorite(photoID string) error {
+  return postOK(c, "flickr.favorites.remove", map[string]string{"photo_id": photoID})
+}
+
+// Returns a page of the favorite photos of a user, including private ones if
+// the caller may see them.  An empty userID means the calling user.  args may
+// contain min_fave_date, max_fave_date, extras, page and per_page.  The
+// photos' DateFaved is set.
+func (c *Client) GetFavorites(userID string, args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.favorites.getList", favoritesArgs(args, userID))
+}
+
+// Returns an iterator over all favorite photos of a user; see GetFavorites.
+func (c *Client) GetFavoritesAll(userID string, args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.favorites.getList", favoritesArgs(args, userID))
+}
+
+// Returns a page of the public favorite photos of a user; see GetFavorites.
+func (c *Client) GetPublicFavorites(userID string, args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.favorites.getPublicList", favoritesArgs(args, userID))
+}
+
+// Returns an iterator over all public favorite photos of a user.
+func (c *Client) GetPublicFavoritesAll(userID string, args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.favorites.getPublicList", favoritesArgs(args, userID))
+}
+
+// Returns a copy of args with user_id set, unless userID is empty.
+func favoritesArgs(args map[string]string, userID string) map[string]string {
+  if userID == "" {
+    return clone(args)
+  }
+  return withArg(args, "user_id", userID)
+}
+
+// Returns URL for flickr.favorites.getContext request.
+func getFavoriteContextURL(c *Client, photoID, userID string) string {
+  args := make(map[string]string)
+  args["photo_id"] = photoID
+  args["user_id"] = userID
+  return makeURL(c, "flickr.favorites.getContext", args, true)
+}
+
+// Returns the photos before and after photoID in the favorites of userID.
+func (c *Client) GetFavoriteContext(photoID, userID string) (*PhotoContext, error) {
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    PhotoContext
+  }{}
+  if err := flickrGet(c, getFavoriteContextURL(c, photoID, userID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.PhotoContext, nil
+}
+
+// A user who marked a photo as favorite.
+type Favorer struct {
+  NSID     string `xml:"nsid,attr"`
+  UserName string `xml:"username,attr"`
+  RealName string `xml:"realname,attr"`
+  FaveDate string `xml:"favedate,attr"` // Unix timestamp
+}
+
+func (f *Favorer) FaveTime() time.Time {
+  return optionalTime(f.FaveDate)
+}
+
+// Response for flickr.photos.getFavorites requests.
+type PhotoFavoritesResponse struct {
+  Paging
+
+  PhotoID string    `xml:"id,attr"`
+  People  []Favorer `xml:"person"`
+}
+
+// Returns URL for flickr.photos.getFavorites request.
+func getPhotoFavoritesURL(c *Client, photoID string, page, perPage int) string {
+  args := make(map[string]string)
+  args["photo_id"] = photoID
+  setPage(args, page, perPage)
+  return makeURL(c, "flickr.photos.getFavorites", args, true)
+}
+
+// Returns a page of the users who marked a photo as favorite, most recent
+// first.  page and perPage are ignored when zero.
+func (c *Client) GetPhotoFavorites(photoID string,
+  page, perPage int) (*PhotoFavoritesResponse, error) {
+  r := struct {
+    Stat     string                 `xml:"stat,attr"`
+    Err      flickrError            `xml:"err"`
+    Response PhotoFavoritesResponse `xml:"photo"`
+  }{}
+  u := getPhotoFavoritesURL(c, photoID, page, perPage)
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Response, nil
+}
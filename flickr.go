@@ -7,6 +7,8 @@ import (
   "net/http"
   "strconv"
   "strings"
+  "sync"
+  "time"
 )
 
 // Flickr API permission levels.  See
@@ -33,6 +35,19 @@ type Client struct {
   // Hint: App engine's Context implements this interface.
   Logger Debugfer
 
+  // If set, Upload checks each file against the user's upload limits (see
+  // GetUploadStatus) before sending it.  The limits are fetched by the first
+  // upload, and again after an hour or when AuthToken changes; in between,
+  // the remaining bandwidth and videos are tracked locally.
+  CheckUploadLimits bool
+
+  // Upload limits for CheckUploadLimits, with the token they were fetched for
+  // and when, guarded by statusMu.
+  uploadStatus *UploadStatus
+  statusToken  string
+  statusTime   time.Time
+  statusMu     sync.Mutex
+
   // API key for your app.
   apiKey string
 
@@ -79,7 +94,18 @@ type flickrError struct {
 }
 
 func (e *flickrError) Err() error {
-  return fmt.Errorf("Flickr error code %s: %s", e.Code, e.Msg)
+  return &Error{Code: e.Code, Msg: e.Msg}
+}
+
+// An error reported by the Flickr API.  See the documentation of each API
+// method for the meaning of Code.
+type Error struct {
+  Code string
+  Msg  string
+}
+
+func (e *Error) Error() string {
+  return fmt.Sprintf("Flickr error code %s: %s", e.Code, e.Msg)
 }
 
 // Exchanges a temporary frob for a token that's valid forever.
@@ -151,6 +177,94 @@ func (c *Client) GetSizes(photoId string) (*SizesResponse, error) {
   return &r.Response, nil
 }
 
+// Sets the title and description of a photo.
+func (c *Client) SetMeta(photoID, title, description string) error {
+  args := map[string]string{
+    "photo_id":    photoID,
+    "title":       title,
+    "description": description,
+  }
+  return postOK(c, "flickr.photos.setMeta", args)
+}
+
+// Layout of taken dates in Flickr requests and responses.
+const takenLayout = "2006-01-02 15:04:05"
+
+// Sets the posted and taken dates of a photo.  A zero time leaves the
+// corresponding date unchanged; granularity applies to the taken date.  The
+// taken date is sent as the wall clock time in taken's location.
+func (c *Client) SetDates(photoID string, posted, taken time.Time,
+  granularity Granularity) error {
+  args := map[string]string{"photo_id": photoID}
+  if !posted.IsZero() {
+    args["date_posted"] = strconv.FormatInt(posted.Unix(), 10)
+  }
+  if !taken.IsZero() {
+    args["date_taken"] = taken.Format(takenLayout)
+    args["date_taken_granularity"] = strconv.Itoa(int(granularity))
+  }
+  return postOK(c, "flickr.photos.setDates", args)
+}
+
+// Sets the content type of a photo.
+func (c *Client) SetContentType(photoID string, t ContentType) error {
+  args := map[string]string{
+    "photo_id":     photoID,
+    "content_type": strconv.Itoa(int(t)),
+  }
+  return postOK(c, "flickr.photos.setContentType", args)
+}
+
+// Sets the safety level of a photo, and whether it is hidden from public
+// searches.
+func (c *Client) SetSafetyLevel(photoID string, level SafetyLevel,
+  hidden bool) error {
+  args := map[string]string{
+    "photo_id":     photoID,
+    "safety_level": strconv.Itoa(int(level)),
+    "hidden":       boolArg(hidden),
+  }
+  return postOK(c, "flickr.photos.setSafetyLevel", args)
+}
+
+// Sets the visibility of a photo and who may comment on it or add metadata.
+func (c *Client) SetPerms(photoID string, p Perms) error {
+  args := map[string]string{
+    "photo_id":     photoID,
+    "is_public":    boolArg(p.IsPublic),
+    "is_friend":    boolArg(p.IsFriend),
+    "is_family":    boolArg(p.IsFamily),
+    "perm_comment": strconv.Itoa(int(p.PermComment)),
+    "perm_addmeta": strconv.Itoa(int(p.PermAddMeta)),
+  }
+  return postOK(c, "flickr.photos.setPerms", args)
+}
+
+// Adds tags to a photo.  Tags containing spaces are quoted as required.
+func (c *Client) AddTags(photoID string, tags []string) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "tags":     joinTags(tags),
+  }
+  return postOK(c, "flickr.photos.addTags", args)
+}
+
+// Replaces all tags of a photo.  Tags containing spaces are quoted as
+// required.
+func (c *Client) SetTags(photoID string, tags []string) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "tags":     joinTags(tags),
+  }
+  return postOK(c, "flickr.photos.setTags", args)
+}
+
+// Removes a tag from a photo.  tagID is the ID of the tag as found in
+// InfoResponse.Tags, not its text.
+func (c *Client) RemoveTag(tagID string) error {
+  return postOK(c, "flickr.photos.removeTag", map[string]string{"tag_id": tagID})
+}
+
 // Searches for photos.  args contains search parameters as described in
 // http://www.flickr.com/services/api/flickr.photos.search.html.
 func searchURL(c *Client, args map[string]string) string {
@@ -174,23 +288,44 @@ func (c *Client) Search(args map[string]string) (*SearchResponse, error) {
     return nil, r.Err.Err()
   }
 
-  for i, ph := range r.Photos.Photos {
+  setRatios(r.Photos.Photos)
+  return &r.Photos, nil
+}
+
+// Sets the Ratio of photos whose thumbnail dimensions are known.
+func setRatios(photos []SearchPhoto) {
+  for i, ph := range photos {
     h, hErr := strconv.ParseFloat(ph.Height_T, 64)
     w, wErr := strconv.ParseFloat(ph.Width_T, 64)
     if hErr == nil && wErr == nil {
-      // ph is apparently just a copy of r.Photos.Photos[i], so we are
-      // updating the original.
-      r.Photos.Photos[i].Ratio = w / h
+      // ph is apparently just a copy of photos[i], so we are updating the
+      // original.
+      photos[i].Ratio = w / h
     }
   }
-  return &r.Photos, nil
 }
 
 // Initiates an asynchronous photo upload and returns the ticket ID.  See
-// http://www.flickr.com/services/api/upload.async.html for details.
+// http://www.flickr.com/services/api/upload.async.html for details.  If
+// c.CheckUploadLimits is set, the upload is validated against the user's
+// upload status first.
 func (c *Client) Upload(name string, photo []byte,
   args map[string]string) (ticketID string, err error) {
+  if c.CheckUploadLimits {
+    release, rErr := c.reserveUpload(name, photo)
+    if rErr != nil {
+      return "", rErr
+    }
+    defer func() {
+      if err != nil {
+        release()
+      }
+    }()
+  }
   req, uErr := uploadRequest(c, name, photo, args)
+  if fErr, ok := uErr.(*UnsupportedFormatError); ok {
+    return "", fErr
+  }
   if uErr != nil {
     return "", wrapErr("request creation failed", uErr)
   }
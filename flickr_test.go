@@ -3,6 +3,8 @@ package flickgo
 import (
   "bytes"
   "crypto/md5"
+  "encoding/json"
+  "encoding/xml"
   "errors"
   "fmt"
   "github.com/op/go-logging"
@@ -11,9 +13,13 @@ import (
   "io/ioutil"
   "net/http"
   "net/url"
+  "os"
+  "path/filepath"
   "strconv"
   "strings"
+  "sync"
   "testing"
+  "time"
 )
 
 var log = logging.MustGetLogger("com.github.octplane.flickgo")
@@ -209,6 +215,86 @@ func TestUploadRequest(t *testing.T) {
   assertEq(t, "photo", string(data), string(actual))
 }
 
+func TestPostRequest(t *testing.T) {
+  c := New(apiKey, secret, nil)
+  c.AuthToken = "ase878723623"
+  req, err := postRequest(c, "flickr.photos.setMeta",
+    map[string]string{"title": "a b&c"})
+  assertOK(t, "postRequest", err)
+  assertEq(t, "method", "POST", req.Method)
+  assertEq(t, "url", "https://api.flickr.com/services/rest/", req.URL.String())
+  assertOK(t, "parseForm", req.ParseForm())
+
+  expected := map[string]string{
+    "method":     "flickr.photos.setMeta",
+    "title":      "a b&c",
+    "api_key":    apiKey,
+    "auth_token": "ase878723623",
+  }
+  sig := sign(secret, expected)
+  expected["api_sig"] = sig
+  assertEq(t, "len", len(expected), len(req.PostForm))
+  for k, v := range expected {
+    assertEq(t, k, v, req.PostForm.Get(k))
+  }
+}
+
+//-----------------------
+// Tests for media.go
+//
+func TestDetectContentType(t *testing.T) {
+  mp2t := make([]byte, 189)
+  mp2t[0], mp2t[188] = 0x47, 0x47
+  m2ts := make([]byte, 4*192+5)
+  for i := 4; i < len(m2ts); i += 192 {
+    m2ts[i] = 0x47
+  }
+  gif := []byte("GIF89a\x01\x00\x01\x00")
+  gif = append(gif, make([]byte, 200)...)
+  gif[188] = 'G'
+  tests := []struct {
+    filename string
+    data     string
+    expected string
+  }{
+    {"a.bin", "\xff\xd8\xff\xe0\x00\x10JFIF", "image/jpeg"},
+    {"a.jpg", "\x89PNG\x0d\x0a\x1a\x0a", "image/png"},
+    {"a", "GIF89a", "image/gif"},
+    {"a.tif", "MM\x00*\x00\x00\x00\x08", "image/tiff"},
+    {"a.heic", "\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic", "image/heic"},
+    {"a", "\x00\x00\x00\x14ftypqt  \x00\x00\x02\x00qt  ", "video/quicktime"},
+    {"a", "\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00isommp42", "video/mp4"},
+    {"a", "\x00\x00\x00\x14ftyp3gp4\x00\x00\x00\x00isom", "video/3gpp"},
+    {"a", "\x00\x00\x00\x08wide\x00\x00\x00\x00mdat", "video/quicktime"},
+    {"a", "RIFF\x00\x00\x00\x00AVI LIST", "video/x-msvideo"},
+    {"a", "\x30\x26\xb2\x75\x8e\x66\xcf\x11\xa6\xd9", "video/x-ms-wmv"},
+    {"a", "\x00\x00\x01\xba\x44\x00", "video/mpeg"},
+    {"a", "OggS\x00\x02", "video/ogg"},
+    {"a", string(mp2t), "video/mp2t"},
+    {"a", string(m2ts), "video/mp2t"},
+    {"a.gif", string(gif), "image/gif"},
+    {"a.MOV", "no magic here", "video/quicktime"},
+  }
+  for _, test := range tests {
+    ct, err := DetectContentType(test.filename, []byte(test.data))
+    assertOK(t, test.expected, err)
+    assertEq(t, test.expected, test.expected, ct)
+  }
+
+  for _, data := range []string{
+    "\x00\x00\x00\x18ftypcrx \x00\x00\x00\x01crx isom",
+    "\x00\x00\x00\x1cftypM4A \x00\x00\x00\x00M4A mp42isom",
+    "\x00\x00\x00\x1cftypavif\x00\x00\x00\x00avifmif1miaf",
+    "\x00\x00\x00\x14ftypabcd\x00\x00\x00\x00abcd",
+    "II*\x00\x08\x00\x00\x00",
+    "BM\x00\x00",
+  } {
+    _, err := DetectContentType("a.dng", []byte(data))
+    _, ok := err.(*UnsupportedFormatError)
+    assert(t, "unsupported "+data, ok)
+  }
+}
+
 //-----------------------
 // Tests for flickr.go
 //
@@ -295,7 +381,7 @@ func TestUploadFails(t *testing.T) {
     return &resp, nil
   }
   c := New(apiKey, secret, newHTTPClient(postFn))
-  ticket, err := c.Upload("filename", []byte("photo content"),
+  ticket, err := c.Upload("filename", []byte("\xff\xd8\xffphoto content"),
     map[string]string{})
   assert(t, "message: "+err.Error(),
     strings.Contains(err.Error(), "code 5: Filetype was not recognised"))
@@ -313,12 +399,30 @@ func TestUpload(t *testing.T) {
     return &resp, nil
   }
   c := New(apiKey, secret, newHTTPClient(postFn))
-  ticket, err := c.Upload("filename", make([]byte, 1024*1024),
+  ticket, err := c.Upload("filename.jpg", make([]byte, 1024*1024),
     map[string]string{})
   assertOK(t, "upload", err)
   assertEq(t, "ticket", "363", ticket)
 }
 
+func TestUploadUnsupportedFormat(t *testing.T) {
+  getFn := func(r *http.Request) (*http.Response, error) {
+    t.Errorf("unexpected request to %v", r.URL)
+    return nil, errors.New("unexpected request")
+  }
+  c := New(apiKey, secret, newHTTPClient(getFn))
+  _, err := c.Upload("notes.txt", []byte("just some text"), nil)
+  fErr, ok := err.(*UnsupportedFormatError)
+  assert(t, "error type", ok)
+  assertEq(t, "filename", "notes.txt", fErr.Filename)
+  assertEq(t, "message", "notes.txt: unrecognised file format", err.Error())
+
+  _, err = c.Upload("IMG_0001.CR2", []byte("II*\x00\x10\x00\x00\x00CR\x02\x00"), nil)
+  assertEq(t, "raw message",
+    "IMG_0001.CR2: RAW files (image/x-canon-cr2) are not accepted by Flickr",
+    err.Error())
+}
+
 func TestSearchURL(t *testing.T) {
   args := map[string]string{
     "per_page": "10",
@@ -397,9 +501,15 @@ func TestInfo(t *testing.T) {
   <notes>
     <note id="313" author="12037949754@N01" authorname="Bees" x="10" y="10" w="50" h="50">foo</note>
   </notes>
+  <people haspeople="1" />
+  <location latitude="47.633" longitude="-122.333" accuracy="16" context="2" place_id="kH8dLOubBZRvX_YZ" woeid="2490383">
+    <locality place_id="kH8dLOubBZRvX_YZ" woeid="2490383">Seattle</locality>
+    <country place_id="nz.gsghTUb4c2WAecA" woeid="23424977">United States</country>
+  </location>
   <tags>
     <tag id="1234" author="12037949754@N01" raw="woo yay">wooyay</tag>
     <tag id="1235" author="12037949754@N01" raw="hoopla">hoopla</tag>
+    <tag id="1236" author="12037949754@N01" authorname="Bees" raw="ourapp:asset=123" machine_tag="1">ourapp:asset=123</tag>
   </tags>
   <urls>
     <url type="photopage">http://www.flickr.com/photos/bees/2733/</url>
@@ -430,6 +540,25 @@ func TestInfo(t *testing.T) {
   assertEq(t, "Dates.Taken", "2004-11-19 12:51:19", r.Dates.Taken)
   assertEq(t, "Dates.Lastupdate", "1093022469", r.Dates.Lastupdate)
   assertEq(t, "Dates.Takengranularity", 0, r.Dates.Takengranularity)
+  assertEq(t, "len(Tags)", 3, len(r.Tags))
+  assertEq(t, "Tags[0].ID", "1234", r.Tags[0].ID)
+  assertEq(t, "Tags[0].Author", "12037949754@N01", r.Tags[0].Author)
+  assertEq(t, "Tags[0].Raw", "woo yay", r.Tags[0].Raw)
+  assertEq(t, "Tags[0].Text", "wooyay", r.Tags[0].Text)
+  assert(t, "Tags[0].MachineTag", !r.Tags[0].MachineTag)
+  assertEq(t, "Tags[2].Raw", "ourapp:asset=123", r.Tags[2].Raw)
+  assert(t, "Tags[2].MachineTag", r.Tags[2].MachineTag)
+  assertEq(t, "len(Notes)", 1, len(r.Notes))
+  assertEq(t, "Notes[0].ID", "313", r.Notes[0].ID)
+  assertEq(t, "Notes[0].AuthorName", "Bees", r.Notes[0].AuthorName)
+  assertEq(t, "Notes[0].Rect", Rect{10, 10, 50, 50}, r.Notes[0].Rect)
+  assertEq(t, "Notes[0].Text", "foo", r.Notes[0].Text)
+  assert(t, "People.HasPeople", r.People.HasPeople)
+  assertEq(t, "Location.Latitude", 47.633, r.Location.Latitude)
+  assertEq(t, "Location.Accuracy", 16, r.Location.Accuracy)
+  assertEq(t, "Location.Context", GeoContextOutdoors, r.Location.Context)
+  assertEq(t, "Location.Locality.Name", "Seattle", r.Location.Locality.Name)
+  assertEq(t, "Location.Country.WOEID", "23424977", r.Location.Country.WOEID)
 
 }
 
@@ -577,4 +706,2611 @@ func TestGetSets(t *testing.T) {
   }
   verify(sets[0], 0, "12345", "Flowers", "All my flower pictures")
   verify(sets[1], 1, "65656", "Sophie", "Photos and videos of Sophie")
+  assertEq(t, "1.photos", 112, sets[1].Photos)
+  assertEq(t, "1.videos", 32, sets[1].Videos)
+}
+
+// Returns a Client that expects a single call to method, checks its arguments
+// against expected and replies with an ok status.
+func newExpectClient(t *testing.T, method string,
+  expected map[string]string) *Client {
+  return newRoutingClient(t, func(m string, args url.Values) string {
+    assertEq(t, "method", method, m)
+    assertEq(t, method+" api_sig", 1, len(args["api_sig"]))
+    for k, v := range expected {
+      assertEq(t, method+" "+k, v, args.Get(k))
+    }
+    return `<rsp stat="ok"/>`
+  })
+}
+
+func TestSetMeta(t *testing.T) {
+  c := newExpectClient(t, "flickr.photos.setMeta", map[string]string{
+    "photo_id": "42", "title": "Kitten", "description": "my cute kitten",
+  })
+  assertOK(t, "SetMeta", c.SetMeta("42", "Kitten", "my cute kitten"))
+}
+
+func TestSetDates(t *testing.T) {
+  c := newExpectClient(t, "flickr.photos.setDates", map[string]string{
+    "photo_id":               "42",
+    "date_posted":            "1100897479",
+    "date_taken":             "2004-11-19 12:51:19",
+    "date_taken_granularity": "4",
+  })
+  taken := time.Date(2004, 11, 19, 12, 51, 19, 0, time.FixedZone("PST", -8*3600))
+  assertOK(t, "SetDates",
+    c.SetDates("42", time.Unix(1100897479, 0), taken, GranularityMonth))
+
+  c = newRoutingClient(t, func(m string, args url.Values) string {
+    _, posted := args["date_posted"]
+    _, taken := args["date_taken"]
+    assert(t, "date_posted", posted)
+    assert(t, "date_taken", !taken)
+    return `<rsp stat="ok"/>`
+  })
+  assertOK(t, "SetDates posted",
+    c.SetDates("42", time.Unix(1100897479, 0), time.Time{}, 0))
+}
+
+func TestSetContentTypeAndSafetyLevel(t *testing.T) {
+  c := newExpectClient(t, "flickr.photos.setContentType",
+    map[string]string{"photo_id": "42", "content_type": "2"})
+  assertOK(t, "SetContentType", c.SetContentType("42", ContentScreenshot))
+
+  c = newExpectClient(t, "flickr.photos.setSafetyLevel",
+    map[string]string{"photo_id": "42", "safety_level": "3", "hidden": "1"})
+  assertOK(t, "SetSafetyLevel", c.SetSafetyLevel("42", SafetyRestricted, true))
+}
+
+func TestSetPerms(t *testing.T) {
+  c := newExpectClient(t, "flickr.photos.setPerms", map[string]string{
+    "photo_id":     "42",
+    "is_public":    "0",
+    "is_friend":    "1",
+    "is_family":    "1",
+    "perm_comment": "2",
+    "perm_addmeta": "0",
+  })
+  p := Perms{
+    Visibility:  Visibility{IsFriend: true, IsFamily: true},
+    PermComment: PermContacts,
+    PermAddMeta: PermNobody,
+  }
+  assertOK(t, "SetPerms", c.SetPerms("42", p))
+}
+
+func TestSetPermsFails(t *testing.T) {
+  c := newRoutingClient(t, func(m string, args url.Values) string {
+    return `<rsp stat="fail"><err code="99" msg="Insufficient permissions"/></rsp>`
+  })
+  err := c.SetPerms("42", Perms{})
+  assertEq(t, "err", "Flickr error code 99: Insufficient permissions",
+    fmt.Sprint(err))
+}
+
+func TestTags(t *testing.T) {
+  tags := []string{"kitten", "black cat"}
+  c := newExpectClient(t, "flickr.photos.addTags",
+    map[string]string{"photo_id": "42", "tags": `kitten "black cat"`})
+  assertOK(t, "AddTags", c.AddTags("42", tags))
+
+  c = newExpectClient(t, "flickr.photos.setTags",
+    map[string]string{"photo_id": "42", "tags": `kitten "black cat"`})
+  assertOK(t, "SetTags", c.SetTags("42", tags))
+
+  c = newExpectClient(t, "flickr.photos.removeTag",
+    map[string]string{"tag_id": "1234-42-5678"})
+  assertOK(t, "RemoveTag", c.RemoveTag("1234-42-5678"))
+}
+
+// Returns a Client whose requests are answered by reply, which is called with
+// the Flickr method name and the request arguments.  Photo uploads are
+// reported with the method name "upload".
+func newRoutingClient(t *testing.T,
+  reply func(method string, args url.Values) string) *Client {
+  var mu sync.Mutex
+  getFn := func(r *http.Request) (*http.Response, error) {
+    var method string
+    var args url.Values
+    switch {
+    case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/upload"):
+      assertOK(t, "parseMultipart", r.ParseMultipartForm(1<<20))
+      method, args = "upload", r.MultipartForm.Value
+    case r.Method == "POST":
+      assertOK(t, "parseForm", r.ParseForm())
+      method, args = r.PostForm.Get("method"), r.PostForm
+    default:
+      args = r.URL.Query()
+      method = args.Get("method")
+    }
+    mu.Lock()
+    defer mu.Unlock()
+    return &http.Response{Body: bodyWithString(reply(method, args))}, nil
+  }
+  return New(apiKey, secret, newHTTPClient(getFn))
+}
+
+//-----------------------
+// Tests for uploader.go
+//
+func TestJoinTags(t *testing.T) {
+  assertEq(t, "tags", `kitten "black cat" me`,
+    joinTags([]string{"kitten", " black cat", `"me"`, ""}))
+}
+
+// Creates n small JPEG files in a new temporary directory.
+func uploadFixtures(t *testing.T, n int) (dir string, jobs []UploadJob) {
+  dir, err := ioutil.TempDir("", "flickgo")
+  assertOK(t, "tempDir", err)
+  for i := 0; i < n; i++ {
+    path := filepath.Join(dir, fmt.Sprintf("photo%d.jpg", i))
+    data := append([]byte{0xff, 0xd8, 0xff, 0xe0}, byte(i))
+    assertOK(t, "writeFile", ioutil.WriteFile(path, data, 0644))
+    jobs = append(jobs, UploadJob{Path: path})
+  }
+  return dir, jobs
+}
+
+func TestUploaderRun(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 5)
+  defer os.RemoveAll(dir)
+  jobs[0].Tags = []string{"two words"}
+  jobs[0].Perms = &Visibility{IsFriend: true}
+
+  uploads, failures := 0, 1
+  var addedToSet []string
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "upload":
+      if args.Get("tags") != "" {
+        assertEq(t, "tags", `"two words"`, args.Get("tags"))
+        assertEq(t, "is_friend", "1", args.Get("is_friend"))
+        assertEq(t, "is_public", "0", args.Get("is_public"))
+      }
+      if failures > 0 {
+        failures--
+        return `<rsp stat="fail"><err code="105" msg="Service currently unavailable"/></rsp>`
+      }
+      uploads++
+      return fmt.Sprintf(`<rsp stat="ok"><ticketid>%d</ticketid></rsp>`, uploads)
+    case "flickr.photos.upload.checkTickets":
+      id := args.Get("tickets")
+      return fmt.Sprintf(`<rsp stat="ok"><uploader>
+        <ticket id="%s" complete="1" photoid="p%s"/></uploader></rsp>`, id, id)
+    case "flickr.photosets.addPhoto":
+      assertEq(t, "photoset_id", "set1", args.Get("photoset_id"))
+      addedToSet = append(addedToSet, args.Get("photo_id"))
+      return `<rsp stat="ok"/>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return `<rsp stat="fail"/>`
+  })
+  for i := range jobs {
+    jobs[i].Actions = []UploadAction{AddToSetAction("set1")}
+  }
+  u := NewUploader(c)
+  u.Workers = 3
+  u.Retries = 1
+  u.RetryDelay = 0
+  u.PollInterval = 0
+  u.JournalPath = filepath.Join(dir, "journal")
+
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "report", "5 uploaded, 0 skipped, 0 failed", report.String())
+  assertEq(t, "uploads", 5, uploads)
+  assertEq(t, "addedToSet", 5, len(addedToSet))
+  attempts := 0
+  for i, r := range report.Results {
+    assertEq(t, fmt.Sprintf("%d.path", i), jobs[i].Path, r.Path)
+    assertEq(t, fmt.Sprintf("%d.photoID", i), "p"+r.TicketID, r.PhotoID)
+    attempts += r.Attempts
+  }
+  assertEq(t, "attempts", 6, attempts)
+
+  // A second run with the same journal must not upload anything again.
+  report, err = u.Run(jobs)
+  assertOK(t, "rerun", err)
+  assertEq(t, "rerun report", "0 uploaded, 5 skipped, 0 failed", report.String())
+  assertEq(t, "rerun uploads", 5, uploads)
+  assertEq(t, "rerun addedToSet", 5, len(addedToSet))
+}
+
+func TestUploaderResumesTicket(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 1)
+  defer os.RemoveAll(dir)
+  journalPath := filepath.Join(dir, "journal")
+  entry := fmt.Sprintf(`{"path":%q,"ticket_id":"77"}`+"\n", jobs[0].Path)
+  assertOK(t, "writeJournal", ioutil.WriteFile(journalPath, []byte(entry), 0644))
+
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.upload.checkTickets", method)
+    assertEq(t, "tickets", "77", args.Get("tickets"))
+    return `<rsp stat="ok"><uploader>
+      <ticket id="77" complete="1" photoid="9000"/></uploader></rsp>`
+  })
+  u := NewUploader(c)
+  u.JournalPath = journalPath
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "attempts", 0, report.Results[0].Attempts)
+  assertEq(t, "photoID", "9000", report.Results[0].PhotoID)
+}
+
+func TestUploaderGivesUp(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 1)
+  defer os.RemoveAll(dir)
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    if method == "upload" {
+      return `<rsp stat="ok"><ticketid>1</ticketid></rsp>`
+    }
+    return `<rsp stat="ok"><uploader><ticket id="1" complete="2"/></uploader></rsp>`
+  })
+  u := NewUploader(c)
+  u.Retries = 2
+  u.RetryDelay = 0
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "failed", 1, report.Failed)
+  assertEq(t, "attempts", 3, report.Results[0].Attempts)
+  assertEq(t, "failures", 1, len(report.Failures()))
+}
+
+func TestUploaderKeepsTicketOnCheckError(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 1)
+  defer os.RemoveAll(dir)
+  uploads, checks := 0, 0
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    if method == "upload" {
+      uploads++
+      return `<rsp stat="ok"><ticketid>1</ticketid></rsp>`
+    }
+    checks++
+    if checks == 1 {
+      return `<rsp stat="fail"><err code="105" msg="Service currently unavailable"/></rsp>`
+    }
+    return `<rsp stat="ok"><uploader><ticket id="1" complete="1" photoid="42"/></uploader></rsp>`
+  })
+  u := NewUploader(c)
+  u.RetryDelay = 0
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "uploads", 1, uploads)
+  assertEq(t, "checks", 2, checks)
+  assertEq(t, "photoID", "42", report.Results[0].PhotoID)
+  assertOK(t, "result", report.Results[0].Err)
+}
+
+func TestUploaderKeepsTicketOnTimeout(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 1)
+  defer os.RemoveAll(dir)
+  uploads := 0
+  complete := "0"
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    if method == "upload" {
+      uploads++
+      return `<rsp stat="ok"><ticketid>1</ticketid></rsp>`
+    }
+    return `<rsp stat="ok"><uploader><ticket id="1" complete="` + complete +
+      `" photoid="42"/></uploader></rsp>`
+  })
+  u := NewUploader(c)
+  u.Retries = 1
+  u.RetryDelay = 0
+  u.PollInterval = 0
+  u.PollTimeout = 0
+  u.JournalPath = filepath.Join(dir, "journal")
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "failed", 1, report.Failed)
+  assertEq(t, "uploads", 1, uploads)
+
+  // The next run resumes the ticket instead of uploading again.
+  complete = "1"
+  report, err = u.Run(jobs)
+  assertOK(t, "rerun", err)
+  assertEq(t, "rerun uploads", 1, uploads)
+  assertEq(t, "rerun photoID", "42", report.Results[0].PhotoID)
+}
+
+func TestUploaderRepairsJournal(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 3)
+  defer os.RemoveAll(dir)
+  journalPath := filepath.Join(dir, "journal")
+  entry := fmt.Sprintf(`{"path":%q,"ticket_id":"1","photo_id":"p1","done":true}`+"\n"+
+    `{"path":%q,"tic`, jobs[0].Path, jobs[1].Path)
+  assertOK(t, "writeJournal", ioutil.WriteFile(journalPath, []byte(entry), 0644))
+
+  // An entry written after the cut-off line must be read back.
+  j, err := openJournal(journalPath)
+  assertOK(t, "openJournal", err)
+  j.put(jobs[2].Path, journalEntry{TicketID: "3", PhotoID: "p3", Done: true})
+  j.close()
+  j, err = openJournal(journalPath)
+  assertOK(t, "reopenJournal", err)
+  assert(t, "done", j.get(jobs[2].Path).Done)
+  j.close()
+
+  uploads := 0
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    if method == "upload" {
+      uploads++
+      return `<rsp stat="ok"><ticketid>2</ticketid></rsp>`
+    }
+    return `<rsp stat="ok"><uploader>
+      <ticket id="2" complete="1" photoid="p2"/></uploader></rsp>`
+  })
+  u := NewUploader(c)
+  u.JournalPath = journalPath
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "report", "1 uploaded, 2 skipped, 0 failed", report.String())
+  report, err = u.Run(jobs)
+  assertOK(t, "rerun", err)
+  assertEq(t, "rerun report", "0 uploaded, 3 skipped, 0 failed", report.String())
+  assertEq(t, "uploads", 1, uploads)
+}
+
+func TestUploaderCompletesJournalLine(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 2)
+  defer os.RemoveAll(dir)
+  journalPath := filepath.Join(dir, "journal")
+  entry := fmt.Sprintf(`{"path":%q,"ticket_id":"1","photo_id":"p1","done":true}`,
+    jobs[0].Path)
+  assertOK(t, "writeJournal", ioutil.WriteFile(journalPath, []byte(entry), 0644))
+
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    if method == "upload" {
+      return `<rsp stat="ok"><ticketid>2</ticketid></rsp>`
+    }
+    return `<rsp stat="ok"><uploader>
+      <ticket id="2" complete="1" photoid="p2"/></uploader></rsp>`
+  })
+  u := NewUploader(c)
+  u.JournalPath = journalPath
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "report", "1 uploaded, 1 skipped, 0 failed", report.String())
+  report, err = u.Run(jobs)
+  assertOK(t, "rerun", err)
+  assertEq(t, "rerun report", "0 uploaded, 2 skipped, 0 failed", report.String())
+}
+
+func TestUploaderFailsFast(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 1)
+  defer os.RemoveAll(dir)
+  text := filepath.Join(dir, "notes.txt")
+  assertOK(t, "writeFile", ioutil.WriteFile(text, []byte("hello"), 0644))
+  jobs = append(jobs, UploadJob{Path: text},
+    UploadJob{Path: filepath.Join(dir, "missing.jpg")})
+
+  uploads := 0
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "upload", method)
+    uploads++
+    return `<rsp stat="fail"><err code="98" msg="Invalid auth token"/></rsp>`
+  })
+  u := NewUploader(c)
+  u.Retries = 2
+  u.RetryDelay = 0
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "failed", 3, report.Failed)
+  assertEq(t, "uploads", 1, uploads)
+  for i, r := range report.Results {
+    assertEq(t, fmt.Sprintf("%d.attempts", i), 1, r.Attempts)
+  }
+  _, ok := report.Results[0].Err.(*Error)
+  assert(t, "flickr error", ok)
+  _, ok = report.Results[1].Err.(*UnsupportedFormatError)
+  assert(t, "unsupported", ok)
+}
+
+func TestUploaderResumesActions(t *testing.T) {
+  dir, jobs := uploadFixtures(t, 1)
+  defer os.RemoveAll(dir)
+  added, tagged := 0, 0
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "upload":
+      return `<rsp stat="ok"><ticketid>1</ticketid></rsp>`
+    case "flickr.photos.upload.checkTickets":
+      return `<rsp stat="ok"><uploader><ticket id="1" complete="1" photoid="42"/></uploader></rsp>`
+    case "flickr.photosets.addPhoto":
+      added++
+      return `<rsp stat="ok"/>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return `<rsp stat="fail"/>`
+  })
+  fail := true
+  jobs[0].Actions = []UploadAction{
+    AddToSetAction("set1"),
+    func(c *Client, photoID string) error {
+      tagged++
+      if fail {
+        return errors.New("connection reset")
+      }
+      return nil
+    },
+  }
+  u := NewUploader(c)
+  u.Retries = 1
+  u.RetryDelay = 0
+  u.JournalPath = filepath.Join(dir, "journal")
+  report, err := u.Run(jobs)
+  assertOK(t, "run", err)
+  assertEq(t, "failed", 1, report.Failed)
+  assertEq(t, "added", 1, added)
+  assertEq(t, "tagged", 2, tagged)
+
+  // The next run only runs the action that failed.
+  fail = false
+  report, err = u.Run(jobs)
+  assertOK(t, "rerun", err)
+  assertEq(t, "rerun report", "1 uploaded, 0 skipped, 0 failed", report.String())
+  assertEq(t, "rerun added", 1, added)
+  assertEq(t, "rerun tagged", 3, tagged)
+}
+
+func TestAddToSetActionAlreadyInSet(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photosets.addPhoto", method)
+    return `<rsp stat="fail"><err code="3" msg="Photo already in set"/></rsp>`
+  })
+  assertOK(t, "AddToSetAction", AddToSetAction("set1")(c, "42"))
+}
+
+//-----------------------
+// Tests for people.go
+//
+const uploadStatusXML = `<?xml version="1.0" encoding="utf-8"?>
+  <rsp stat="ok">
+    <user id="12037949754@N01" ispro="0">
+      <username>Bees</username>
+      <bandwidth maxbytes="314572800" maxkb="307200" usedbytes="314572000" usedkb="307199" remainingbytes="800" remainingkb="0" unlimited="0"/>
+      <filesize maxbytes="1000" maxkb="1" maxmb="0"/>
+      <videosize maxbytes="5000" maxkb="5" maxmb="0"/>
+      <sets created="3" remaining="lots"/>
+      <videos uploaded="2" remaining="0"/>
+    </user>
+  </rsp>`
+
+func TestGetUploadStatus(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.people.getUploadStatus", method)
+    assertEq(t, "api_sig", 1, len(args["api_sig"]))
+    return uploadStatusXML
+  })
+  s, err := c.GetUploadStatus()
+  assertOK(t, "GetUploadStatus", err)
+  assertEq(t, "nsid", "12037949754@N01", s.NSID)
+  assertEq(t, "username", "Bees", s.UserName)
+  assert(t, "ispro", !s.IsPro)
+  assertEq(t, "bandwidth.max", int64(314572800), s.Bandwidth.MaxBytes)
+  assertEq(t, "bandwidth.remaining", int64(800), s.Bandwidth.RemainingBytes)
+  assert(t, "bandwidth.unlimited", !s.Bandwidth.Unlimited)
+  assertEq(t, "filesize", int64(1000), s.FileSize.MaxBytes)
+  assertEq(t, "videosize", int64(5000), s.VideoSize.MaxBytes)
+  assertEq(t, "sets.created", 3, s.Sets.Created)
+  assertEq(t, "sets.remaining", "lots", s.Sets.Remaining)
+  assertEq(t, "videos.remaining", "0", s.Videos.Remaining)
+}
+
+func TestUploadStatusCheck(t *testing.T) {
+  s := UploadStatus{}
+  s.Bandwidth.MaxBytes = 1000
+  s.Bandwidth.RemainingBytes = 800
+  s.FileSize.MaxBytes = 500
+  s.VideoSize.MaxBytes = 5000
+  s.Videos.Remaining = "lots"
+  jpeg := func(n int) []byte {
+    return append([]byte("\xff\xd8\xff"), make([]byte, n-3)...)
+  }
+  mpeg := append([]byte("\x00\x00\x01\xba"), make([]byte, 696)...)
+
+  assertOK(t, "small photo", s.Check("a.jpg", jpeg(400)))
+  assertEq(t, "large photo", "a.jpg: photo is 600 bytes, maximum is 500",
+    fmt.Sprint(s.Check("a.jpg", jpeg(600))))
+  assertEq(t, "bandwidth",
+    "a.mpg: file is 900 bytes, only 800 bytes of upload bandwidth remaining",
+    fmt.Sprint(s.Check("a.mpg", append(mpeg, make([]byte, 200)...))))
+  assertOK(t, "video", s.Check("a.mpg", mpeg))
+  s.Videos.Remaining = "0"
+  assertEq(t, "no videos", "a.mpg: no video uploads remaining",
+    fmt.Sprint(s.Check("a.mpg", mpeg)))
+  s.Bandwidth.Unlimited = true
+  assertOK(t, "unlimited", s.Check("a.jpg", jpeg(500)))
+  s.Bandwidth.Unlimited = false
+  s.Bandwidth.MaxBytes = 0
+  s.Bandwidth.RemainingBytes = 0
+  assertOK(t, "no bandwidth limit", s.Check("a.jpg", jpeg(500)))
+}
+
+func TestUploadChecksLimits(t *testing.T) {
+  statusCalls, uploads := 0, 0
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.people.getUploadStatus":
+      statusCalls++
+      return uploadStatusXML
+    case "upload":
+      uploads++
+      return `<rsp stat="ok"><ticketid>1</ticketid></rsp>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return `<rsp stat="fail"/>`
+  })
+  c.CheckUploadLimits = true
+  photo := append([]byte("\xff\xd8\xff"), make([]byte, 2000)...)
+  _, err := c.Upload("big.jpg", photo, nil)
+  assertEq(t, "err", "big.jpg: photo is 2003 bytes, maximum is 1000",
+    fmt.Sprint(err))
+  _, ok := err.(*UploadLimitError)
+  assert(t, "limit error", ok)
+
+  photo = append([]byte("\xff\xd8\xff"), make([]byte, 497)...)
+  _, err = c.Upload("a.jpg", photo, nil)
+  assertOK(t, "Upload", err)
+  _, err = c.Upload("b.jpg", photo, nil)
+  assertEq(t, "bandwidth",
+    "b.jpg: file is 500 bytes, only 300 bytes of upload bandwidth remaining",
+    fmt.Sprint(err))
+  assertEq(t, "status calls", 1, statusCalls)
+  assertEq(t, "uploads", 1, uploads)
+
+  // Limits are fetched again for another user, and when stale.
+  c.AuthToken = "other"
+  _, err = c.Upload("c.jpg", photo, nil)
+  assertOK(t, "other user", err)
+  assertEq(t, "other user status calls", 2, statusCalls)
+  c.statusTime = c.statusTime.Add(-2 * time.Hour)
+  _, err = c.Upload("d.jpg", photo, nil)
+  assertOK(t, "stale status", err)
+  assertEq(t, "stale status calls", 3, statusCalls)
+}
+
+func TestUploadCountsVideos(t *testing.T) {
+  statusXML := strings.Replace(uploadStatusXML, `uploaded="2" remaining="0"`,
+    `uploaded="2" remaining="1"`, 1)
+  fail := true
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.people.getUploadStatus":
+      return statusXML
+    case "upload":
+      if fail {
+        fail = false
+        return `<rsp stat="fail"><err code="105" msg="Service currently unavailable"/></rsp>`
+      }
+      return `<rsp stat="ok"><ticketid>1</ticketid></rsp>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return `<rsp stat="fail"/>`
+  })
+  c.CheckUploadLimits = true
+  video := append([]byte("\x00\x00\x01\xba"), make([]byte, 96)...)
+
+  // A failed upload gives back the video it took.
+  _, err := c.Upload("a.mpg", video, nil)
+  assert(t, "failed upload", err != nil)
+  _, err = c.Upload("a.mpg", video, nil)
+  assertOK(t, "Upload", err)
+  assertEq(t, "remaining", "0", c.uploadStatus.Videos.Remaining)
+  assertEq(t, "uploaded", 3, c.uploadStatus.Videos.Uploaded)
+  _, err = c.Upload("b.mpg", video, nil)
+  assertEq(t, "no videos", "b.mpg: no video uploads remaining", fmt.Sprint(err))
+}
+
+func TestFindUser(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.people.findByUsername":
+      assertEq(t, "username", "Stewart", args.Get("username"))
+    case "flickr.people.findByEmail":
+      assertEq(t, "find_email", "stewart@example.com", args.Get("find_email"))
+    case "flickr.urls.lookupUser":
+      assertEq(t, "url", "http://www.flickr.com/photos/stewart/", args.Get("url"))
+      return `<rsp stat="ok"><user id="12037949632@N01">
+        <username>Stewart</username></user></rsp>`
+    default:
+      t.Errorf("unexpected method %s", method)
+    }
+    return `<rsp stat="ok"><user id="12037949632@N01" nsid="12037949632@N01">
+      <username>Stewart</username></user></rsp>`
+  })
+  for _, find := range []func() (*User, error){
+    func() (*User, error) { return c.FindUserByUsername("Stewart") },
+    func() (*User, error) { return c.FindUserByEmail("stewart@example.com") },
+    func() (*User, error) { return c.LookupUser("http://www.flickr.com/photos/stewart/") },
+  } {
+    u, err := find()
+    assertOK(t, "find", err)
+    assertEq(t, "nsid", "12037949632@N01", u.NSID)
+    assertEq(t, "username", "Stewart", u.UserName)
+  }
+}
+
+func TestGetPersonInfo(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.people.getInfo", method)
+    assertEq(t, "user_id", "12037949754@N01", args.Get("user_id"))
+    return `<rsp stat="ok">
+      <person id="12037949754@N01" nsid="12037949754@N01" ispro="1"
+          iconserver="122" iconfarm="1" path_alias="bees">
+        <username>bees</username>
+        <realname>Cal Henderson</realname>
+        <mbox_sha1sum>eea6cd28e3d0003ab51b0058a684d94980b727ac</mbox_sha1sum>
+        <location>Vancouver, Canada</location>
+        <photosurl>http://www.flickr.com/photos/bees/</photosurl>
+        <profileurl>http://www.flickr.com/people/bees/</profileurl>
+        <photos>
+          <firstdate>1071510391</firstdate>
+          <firstdatetaken>1900-09-02 09:11:24</firstdatetaken>
+          <count>449</count>
+        </photos>
+      </person>
+    </rsp>`
+  })
+  u, err := c.GetPersonInfo("12037949754@N01")
+  assertOK(t, "GetPersonInfo", err)
+  assertEq(t, "username", "bees", u.UserName)
+  assertEq(t, "realname", "Cal Henderson", u.RealName)
+  assertEq(t, "location", "Vancouver, Canada", u.Location)
+  assertEq(t, "path_alias", "bees", u.PathAlias)
+  assert(t, "ispro", u.IsPro)
+  assertEq(t, "profileurl", "http://www.flickr.com/people/bees/", u.ProfileURL)
+  assertEq(t, "count", 449, u.Photos.Count)
+  assertEq(t, "firstdate", int64(1071510391), u.Photos.FirstTime().Unix())
+  assertEq(t, "firstdatetaken", "1900-09-02 09:11:24", u.Photos.FirstDateTaken)
+  assertEq(t, "icon",
+    "http://farm1.static.flickr.com/122/buddyicons/12037949754@N01.jpg",
+    u.BuddyIconURL())
+  u.IconServer = "0"
+  assertEq(t, "default icon", "http://www.flickr.com/images/buddyicon.gif",
+    u.BuddyIconURL())
+}
+
+func TestGetUserProfileURL(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.urls.getUserProfile", method)
+    return `<rsp stat="ok">
+      <user nsid="12037949754@N01" url="http://www.flickr.com/people/bees/"/>
+    </rsp>`
+  })
+  u, err := c.GetUserProfileURL("12037949754@N01")
+  assertOK(t, "GetUserProfileURL", err)
+  assertEq(t, "url", "http://www.flickr.com/people/bees/", u)
+}
+
+//-----------------------
+// Tests for machinetags.go
+//
+func TestParseMachineTag(t *testing.T) {
+  m, err := ParseMachineTag("ourapp:asset=123")
+  assertOK(t, "parse", err)
+  assertEq(t, "tag", MachineTag{"ourapp", "asset", "123"}, m)
+  assertEq(t, "string", "ourapp:asset=123", m.String())
+
+  m, err = ParseMachineTag(`dc:title="Orford castle"`)
+  assertOK(t, "parse quoted", err)
+  assertEq(t, "value", "Orford castle", m.Value)
+  assertEq(t, "string quoted", `dc:title="Orford castle"`, m.String())
+
+  m, err = ParseMachineTag(`"dc:title=Orford castle"`)
+  assertOK(t, "parse tag quoted", err)
+  assertEq(t, "tag quoted", "Orford castle", m.Value)
+
+  for _, s := range []string{"kitten", "ns:pred=", "1ns:pred=v", "ns:pr-ed=v"} {
+    _, err := ParseMachineTag(s)
+    assert(t, "invalid "+s, err != nil)
+  }
+}
+
+func TestSetMachineTagSearch(t *testing.T) {
+  args := map[string]string{"user_id": "me"}
+  SetMachineTagSearch(args, MachineTagModeAll,
+    MachineTag{Namespace: "ourapp", Predicate: "asset", Value: "12 3"},
+    MachineTag{Namespace: "ourapp", Predicate: "batch"},
+    MachineTag{Namespace: "ourapp"},
+    MachineTag{Predicate: "asset"})
+  assertEq(t, "machine_tags", `ourapp:asset="12 3",ourapp:batch=,ourapp:,*:asset=`,
+    args["machine_tags"])
+  assertEq(t, "machine_tag_mode", "all", args["machine_tag_mode"])
+  assertEq(t, "user_id", "me", args["user_id"])
+}
+
+func TestInfoMachineTags(t *testing.T) {
+  r := InfoResponse{Tags: []Tag{
+    {Raw: "woo yay", Text: "wooyay"},
+    {Raw: "ourapp:asset=123", MachineTag: true},
+    {Text: "ourapp:batch=7", MachineTag: true},
+    {Raw: "other:asset=9", MachineTag: true},
+  }}
+  assertEq(t, "all", 3, len(r.MachineTags("", "")))
+  assertEq(t, "namespace", 2, len(r.MachineTags("ourapp", "")))
+  assetTags := r.MachineTags("", "asset")
+  assertEq(t, "predicate", 2, len(assetTags))
+  assertEq(t, "both", MachineTag{"ourapp", "asset", "123"},
+    r.MachineTags("ourapp", "asset")[0])
+}
+
+func TestGetMachineTagValues(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.machinetags.getValues", method)
+    assertEq(t, "namespace", "upcoming", args.Get("namespace"))
+    assertEq(t, "predicate", "event", args.Get("predicate"))
+    assertEq(t, "page", "2", args.Get("page"))
+    _, ok := args["per_page"]
+    assert(t, "per_page", !ok)
+    return `<rsp stat="ok">
+      <values namespace="upcoming" predicate="event" page="2" total="3" perpage="2" pages="2">
+        <value usage="3">123</value>
+      </values>
+    </rsp>`
+  })
+  r, err := c.GetMachineTagValues("upcoming", "event", 2, 0)
+  assertOK(t, "GetMachineTagValues", err)
+  assertEq(t, "page", 2, r.Page)
+  assertEq(t, "total", 3, r.Total)
+  assertEq(t, "len", 1, len(r.Values))
+  assertEq(t, "value", MachineTagValue{"123", 3}, r.Values[0])
+}
+
+func TestGetMachineTagNamespacesAndPairs(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.machinetags.getNamespaces":
+      return `<rsp stat="ok"><namespaces page="1" total="2" perpage="500" pages="1">
+        <namespace usage="6538" predicates="13">aero</namespace>
+        <namespace usage="9072" predicates="24">flickr</namespace>
+      </namespaces></rsp>`
+    case "flickr.machinetags.getPredicates":
+      return `<rsp stat="ok"><predicates page="1" total="1" perpage="500" pages="1">
+        <predicate usage="7" namespaces="1">elbow</predicate>
+      </predicates></rsp>`
+    }
+    assertEq(t, "namespace", "aero", args.Get("namespace"))
+    return `<rsp stat="ok"><pairs page="1" total="1" perpage="500" pages="1">
+      <pair namespace="aero" predicate="airline" usage="1093">aero:airline</pair>
+    </pairs></rsp>`
+  })
+  ns, err := c.GetMachineTagNamespaces("", 0, 0)
+  assertOK(t, "namespaces", err)
+  assertEq(t, "namespaces", 2, len(ns.Namespaces))
+  assertEq(t, "namespace", MachineTagNamespace{"flickr", 9072, 24}, ns.Namespaces[1])
+  preds, err := c.GetMachineTagPredicates("", 0, 0)
+  assertOK(t, "predicates", err)
+  assertEq(t, "predicate", MachineTagPredicate{"elbow", 7, 1}, preds.Predicates[0])
+  pairs, err := c.GetMachineTagPairs("aero", "", 0, 0)
+  assertOK(t, "pairs", err)
+  assertEq(t, "pair", MachineTagPair{"aero", "airline", 1093}, pairs.Pairs[0])
+}
+
+//-----------------------
+// Tests for iterator.go
+//
+// Returns a search response page containing photos with the given IDs.
+func searchPage(page, pages int, ids ...string) string {
+  photos := ""
+  for _, id := range ids {
+    photos += fmt.Sprintf(`<photo id="%s" owner="22@N01" secret="1" server="2" farm="3" title="t"/>`, id)
+  }
+  return fmt.Sprintf(`<rsp stat="ok"><photos page="%d" pages="%d" perpage="2" total="5">%s</photos></rsp>`,
+    page, pages, photos)
+}
+
+func TestSearchAll(t *testing.T) {
+  pages := [][]string{{"1", "2"}, {"3", "4"}, {"5"}}
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.search", method)
+    assertEq(t, "tags", "kitten", args.Get("tags"))
+    page, _ := strconv.Atoi(args.Get("page"))
+    return searchPage(page, len(pages), pages[page-1]...)
+  })
+  it := c.SearchAll(map[string]string{"tags": "kitten", "page": "7"})
+  var ids []string
+  for it.Next() {
+    ids = append(ids, it.Photo().ID)
+  }
+  assertOK(t, "err", it.Err())
+  assertEq(t, "ids", "1,2,3,4,5", strings.Join(ids, ","))
+}
+
+func TestSearchAllFails(t *testing.T) {
+  calls := 0
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    calls++
+    if calls == 2 {
+      return `<rsp stat="fail"><err code="105" msg="Service currently unavailable"/></rsp>`
+    }
+    return searchPage(calls, 3, "1")
+  })
+  it := c.SearchAll(map[string]string{})
+  n := 0
+  for it.Next() {
+    n++
+  }
+  assertEq(t, "photos", 1, n)
+  assertEq(t, "err", "Flickr error code 105: Service currently unavailable",
+    fmt.Sprint(it.Err()))
+  assert(t, "next after error", !it.Next())
+  assertEq(t, "calls", 2, calls)
+}
+
+//-----------------------
+// Tests for delete.go
+//
+func TestBulkDelete(t *testing.T) {
+  var deleted []string
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.delete", method)
+    id := args.Get("photo_id")
+    if id == "2" {
+      return `<rsp stat="fail"><err code="1" msg="Photo not found"/></rsp>`
+    }
+    deleted = append(deleted, id)
+    return `<rsp stat="ok"/>`
+  })
+  ids := []string{"1", "2", "3"}
+
+  results, err := c.BulkDelete(ids, DeleteOptions{DryRun: true})
+  assertOK(t, "dry run", err)
+  assertEq(t, "dry run results", 3, len(results))
+  assert(t, "dry run deleted", !results[0].Deleted)
+
+  _, err = c.BulkDelete(ids, DeleteOptions{})
+  assertEq(t, "no confirm", ErrDeleteNotConfirmed, err)
+  _, err = c.BulkDelete(ids, DeleteOptions{
+    Confirm: func([]string) bool { return false },
+  })
+  assertEq(t, "declined", ErrDeleteNotConfirmed, err)
+  assertEq(t, "nothing deleted", 0, len(deleted))
+
+  var confirmed []string
+  start := time.Now()
+  results, err = c.BulkDelete(ids, DeleteOptions{
+    Confirm:  func(ids []string) bool { confirmed = ids; return true },
+    Interval: 10 * time.Millisecond,
+  })
+  assertOK(t, "delete", err)
+  assert(t, "rate limited", time.Since(start) >= 20*time.Millisecond)
+  assertEq(t, "confirmed", 3, len(confirmed))
+  assertEq(t, "deleted", "1,3", strings.Join(deleted, ","))
+  assert(t, "0.deleted", results[0].Deleted)
+  assert(t, "1.deleted", !results[1].Deleted)
+  assertEq(t, "1.err", "Flickr error code 1: Photo not found",
+    fmt.Sprint(results[1].Err))
+  assert(t, "2.deleted", results[2].Deleted)
+}
+
+func TestBulkDeleteIter(t *testing.T) {
+  var deleted []string
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    if method == "flickr.photos.search" {
+      assertEq(t, "no deletes while listing", 0, len(deleted))
+      page, _ := strconv.Atoi(args.Get("page"))
+      return searchPage(page, 2, fmt.Sprint(page))
+    }
+    deleted = append(deleted, args.Get("photo_id"))
+    return `<rsp stat="ok"/>`
+  })
+  results, err := c.BulkDeleteIter(c.SearchAll(map[string]string{}),
+    DeleteOptions{Confirm: func([]string) bool { return true }})
+  assertOK(t, "delete", err)
+  assertEq(t, "results", 2, len(results))
+  assertEq(t, "deleted", "1,2", strings.Join(deleted, ","))
+}
+
+//-----------------------
+// Tests for photosets.go
+//
+func TestCreateSet(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photosets.create", method)
+    assertEq(t, "title", "Flowers", args.Get("title"))
+    assertEq(t, "primary_photo_id", "42", args.Get("primary_photo_id"))
+    return `<rsp stat="ok">
+      <photoset id="1234" url="http://www.flickr.com/photos/bees/sets/1234/"/>
+    </rsp>`
+  })
+  id, err := c.CreateSet("Flowers", "", "42")
+  assertOK(t, "CreateSet", err)
+  assertEq(t, "id", "1234", id)
+}
+
+func TestEditSets(t *testing.T) {
+  c := newExpectClient(t, "flickr.photosets.editPhotos", map[string]string{
+    "photoset_id": "1234", "primary_photo_id": "2", "photo_ids": "1,2,3",
+  })
+  assertOK(t, "EditSetPhotos",
+    c.EditSetPhotos("1234", "2", []string{"1", "2", "3"}))
+
+  c = newExpectClient(t, "flickr.photosets.removePhotos",
+    map[string]string{"photoset_id": "1234", "photo_ids": "1,3"})
+  assertOK(t, "RemovePhotosFromSet",
+    c.RemovePhotosFromSet("1234", []string{"1", "3"}))
+
+  c = newExpectClient(t, "flickr.photosets.removePhoto",
+    map[string]string{"photoset_id": "1234", "photo_id": "3"})
+  assertOK(t, "RemovePhotoFromSet", c.RemovePhotoFromSet("3", "1234"))
+
+  c = newExpectClient(t, "flickr.photosets.reorderPhotos",
+    map[string]string{"photoset_id": "1234", "photo_ids": "3,1"})
+  assertOK(t, "ReorderSetPhotos", c.ReorderSetPhotos("1234", []string{"3", "1"}))
+
+  c = newExpectClient(t, "flickr.photosets.orderSets",
+    map[string]string{"photoset_ids": "9,8"})
+  assertOK(t, "OrderSets", c.OrderSets([]string{"9", "8"}))
+
+  c = newExpectClient(t, "flickr.photosets.editMeta", map[string]string{
+    "photoset_id": "1234", "title": "Flowers", "description": "All of them",
+  })
+  assertOK(t, "EditSetMeta", c.EditSetMeta("1234", "Flowers", "All of them"))
+}
+
+func TestGetSetInfo(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photosets.getInfo", method)
+    assertEq(t, "photoset_id", "72157624618609504", args.Get("photoset_id"))
+    return `<rsp stat="ok">
+      <photoset id="72157624618609504" owner="34427469121@N01" username="George"
+        primary="4847770787" secret="6abd09a292" server="4153" farm="5"
+        photos="55" count_views="523" count_comments="1" count_photos="43"
+        count_videos="12" can_comment="1" date_create="1280530593"
+        date_update="1308091378">
+        <title>Mah Kittehs</title>
+        <description>Sixty and Niner.</description>
+      </photoset>
+    </rsp>`
+  })
+  s, err := c.GetSetInfo("72157624618609504")
+  assertOK(t, "GetSetInfo", err)
+  assertEq(t, "title", "Mah Kittehs", s.Title)
+  assertEq(t, "owner", "34427469121@N01", s.Owner)
+  assertEq(t, "ownername", "George", s.OwnerName)
+  assertEq(t, "photos", 55, s.Photos)
+  assertEq(t, "count_views", 523, s.CountViews)
+  assert(t, "can_comment", s.CanComment)
+  assertEq(t, "created", int64(1280530593), s.CreateTime().Unix())
+  assertEq(t, "primary url",
+    "http://farm5.static.flickr.com/4153/4847770787_6abd09a292_s.jpg",
+    s.PrimaryPhoto().URL(SizeSmallSquare))
+
+  empty := PhotoSet{}
+  assert(t, "no date", empty.UpdateTime().IsZero())
+  bad := PhotoSet{DateCreate: "bad"}
+  assert(t, "bad date", bad.CreateTime().IsZero())
+}
+
+func TestGetSetContext(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photosets.getContext", method)
+    return `<rsp stat="ok">
+      <count>3</count>
+      <prevphoto id="2980" secret="973da1e709" title="boo!"
+        url="/photos/bees/2980/" thumb="http://farm1.static.flickr.com/1/2980_973da1e709_s.jpg"/>
+      <nextphoto id="0"/>
+    </rsp>`
+  })
+  ctx, err := c.GetSetContext("2983", "1234")
+  assertOK(t, "GetSetContext", err)
+  assertEq(t, "prev.id", "2980", ctx.Prev.ID)
+  assertEq(t, "prev.title", "boo!", ctx.Prev.Title)
+  assertEq(t, "prev.url", "/photos/bees/2980/", ctx.Prev.PageURL)
+  assertEq(t, "next.id", "0", ctx.Next.ID)
+}
+
+func TestGetSetPhotos(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photosets.getPhotos", method)
+    assertEq(t, "photoset_id", "4", args.Get("photoset_id"))
+    assertEq(t, "extras", "media", args.Get("extras"))
+    assertEq(t, "privacy_filter", PrivacyFriends, args.Get("privacy_filter"))
+    page := args.Get("page")
+    if page == "2" {
+      return `<rsp stat="ok">
+        <photoset id="4" primary="2483" owner="23@N01" ownername="bees"
+            page="2" perpage="2" pages="2" total="3" title="Test">
+          <photo id="2486" secret="123456" server="1" farm="1" title="3" isprimary="0" media="video"/>
+        </photoset>
+      </rsp>`
+    }
+    return `<rsp stat="ok">
+      <photoset id="4" primary="2483" owner="23@N01" ownername="bees"
+          page="1" perpage="2" pages="2" total="3" title="Test">
+        <photo id="2483" secret="123456" server="1" farm="1" title="1" isprimary="1" media="photo"/>
+        <photo id="2484" secret="123456" server="1" farm="1" title="2" isprimary="0" media="photo"/>
+      </photoset>
+    </rsp>`
+  })
+  args := map[string]string{"extras": "media", "privacy_filter": PrivacyFriends}
+  r, err := c.GetSetPhotos("4", args)
+  assertOK(t, "GetSetPhotos", err)
+  assertEq(t, "title", "Test", r.Title)
+  assertEq(t, "ownername", "bees", r.OwnerName)
+  assertEq(t, "pages", "2", r.Pages)
+  assertEq(t, "len", 2, len(r.Photos))
+  assertEq(t, "owner", "23@N01", r.Photos[0].Owner)
+  assert(t, "isprimary", r.Photos[0].IsPrimary)
+  assertEq(t, "media", "photo", r.Photos[1].Media)
+
+  it := c.GetSetPhotosAll("4", args)
+  var ids []string
+  for it.Next() {
+    ids = append(ids, it.Photo().ID)
+  }
+  assertOK(t, "iterate", it.Err())
+  assertEq(t, "ids", "2483,2484,2486", strings.Join(ids, ","))
+}
+
+func TestGetSetList(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photosets.getList", method)
+    assertEq(t, "user_id", "me", args.Get("user_id"))
+    assertEq(t, "page", "2", args.Get("page"))
+    assertEq(t, "primary_photo_extras", "url_m", args.Get("primary_photo_extras"))
+    return `<rsp stat="ok">
+      <photosets page="2" pages="2" perpage="1" total="2" cancreate="1">
+        <photoset id="65656" primary="2484" secret="1" server="2" farm="3" photos="112" videos="32">
+          <title>Sophie</title>
+          <description>Photos and videos of Sophie</description>
+          <primary_photo_extras width_t="100" height_t="75"/>
+        </photoset>
+      </photosets>
+    </rsp>`
+  })
+  r, err := c.GetSetList("me",
+    map[string]string{"page": "2", "primary_photo_extras": "url_m"})
+  assertOK(t, "GetSetList", err)
+  assertEq(t, "page", 2, r.Page)
+  assertEq(t, "total", 2, r.Total)
+  assert(t, "cancreate", r.CanCreate)
+  assertEq(t, "len", 1, len(r.Sets))
+  assertEq(t, "title", "Sophie", r.Sets[0].Title)
+  assertEq(t, "extras", "100", r.Sets[0].PrimaryPhotoExtras.Width_T)
+}
+
+//-----------------------
+// Tests for setsync.go
+//
+// Fake Flickr holding a single photo set, for sync tests.
+type fakeSets struct {
+  t       *testing.T
+  id      string
+  primary string
+  photos  []string
+  calls   []string
+}
+
+func (f *fakeSets) reply(method string, args url.Values) string {
+  f.calls = append(f.calls, strings.TrimPrefix(method, "flickr.photosets."))
+  ok := `<rsp stat="ok"/>`
+  switch method {
+  case "flickr.photosets.getList":
+    if f.id == "" {
+      return `<rsp stat="ok"><photosets page="1" pages="1" total="0"/></rsp>`
+    }
+    return fmt.Sprintf(`<rsp stat="ok"><photosets page="1" pages="1" total="2">
+      <photoset id="1"><title>Other</title></photoset>
+      <photoset id="%s" primary="%s"><title>Kittens</title></photoset>
+    </photosets></rsp>`, f.id, f.primary)
+  case "flickr.photosets.getPhotos":
+    assertEq(f.t, "photoset_id", f.id, args.Get("photoset_id"))
+    photos := ""
+    for _, id := range f.photos {
+      photos += fmt.Sprintf(`<photo id="%s"/>`, id)
+    }
+    return fmt.Sprintf(`<rsp stat="ok"><photoset id="%s" page="1" pages="1">%s</photoset></rsp>`,
+      f.id, photos)
+  case "flickr.photosets.create":
+    f.id, f.primary = "99", args.Get("primary_photo_id")
+    f.photos = []string{f.primary}
+    return `<rsp stat="ok"><photoset id="99"/></rsp>`
+  case "flickr.photosets.addPhoto":
+    f.photos = append(f.photos, args.Get("photo_id"))
+    return ok
+  case "flickr.photosets.setPrimaryPhoto":
+    f.primary = args.Get("photo_id")
+    return ok
+  case "flickr.photosets.removePhotos":
+    var kept []string
+    for _, id := range f.photos {
+      if !strings.Contains(","+args.Get("photo_ids")+",", ","+id+",") {
+        kept = append(kept, id)
+      }
+    }
+    f.photos = kept
+    return ok
+  case "flickr.photosets.reorderPhotos":
+    f.photos = strings.Split(args.Get("photo_ids"), ",")
+    return ok
+  }
+  f.t.Errorf("unexpected method %s", method)
+  return `<rsp stat="fail"/>`
+}
+
+func TestSyncSetCreates(t *testing.T) {
+  f := &fakeSets{t: t}
+  c := newRoutingClient(t, f.reply)
+  d, err := c.SyncSet("me", "Kittens", []string{"3", "1", "3", "2"}, true)
+  assertOK(t, "dry run", err)
+  assert(t, "create", d.Create)
+  assertEq(t, "dry run calls", "getList", strings.Join(f.calls, ","))
+  assertEq(t, "diff", "set \"Kittens\" (new)\n  create with primary photo 3\n  + 1\n  + 2\n",
+    d.String())
+
+  d, err = c.SyncSet("me", "Kittens", []string{"3", "1", "3", "2"}, false)
+  assertOK(t, "sync", err)
+  assertEq(t, "set id", "99", d.SetID)
+  assertEq(t, "photos", "3,1,2", strings.Join(f.photos, ","))
+}
+
+func TestSyncSetUpdates(t *testing.T) {
+  f := &fakeSets{t: t, id: "7", primary: "1", photos: []string{"1", "2", "3"}}
+  c := newRoutingClient(t, f.reply)
+  d, err := c.SyncSet("me", "Kittens", []string{"4", "3", "2"}, true)
+  assertOK(t, "dry run", err)
+  assertEq(t, "diff", "set \"Kittens\" (7)\n  + 4\n  - 1\n  reorder 3 photos\n",
+    d.String())
+  assertEq(t, "unchanged", "1,2,3", strings.Join(f.photos, ","))
+
+  _, err = c.SyncSet("me", "Kittens", []string{"4", "3", "2"}, false)
+  assertOK(t, "sync", err)
+  assertEq(t, "photos", "4,3,2", strings.Join(f.photos, ","))
+  assertEq(t, "primary", "4", f.primary)
+
+  d, err = c.SyncSet("me", "Kittens", []string{"4", "3", "2"}, false)
+  assertOK(t, "resync", err)
+  assert(t, "empty", d.Empty())
+  assertEq(t, "up to date", "set \"Kittens\" (7)\n  up to date\n", d.String())
+
+  _, err = c.SyncSet("me", "Kittens", nil, false)
+  assert(t, "empty set", err != nil)
+}
+
+//-----------------------
+// Tests for collections.go
+//
+func TestGetCollectionTree(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.collections.getTree", method)
+    _, ok := args["collection_id"]
+    assert(t, "collection_id", !ok)
+    assertEq(t, "user_id", "12@N01", args.Get("user_id"))
+    return `<rsp stat="ok"><collections>
+      <collection id="12-1" title="Archive" description="Everything" iconsmall="s.jpg">
+        <collection id="12-2" title="2010">
+          <set id="101" title="January" description="cold"/>
+          <set id="102" title="February" description=""/>
+        </collection>
+        <collection id="12-3" title="2011">
+          <collection id="12-4" title="Travel">
+            <set id="103" title="Japan" description=""/>
+          </collection>
+        </collection>
+      </collection>
+      <collection id="12-5" title="Misc"/>
+    </collections></rsp>`
+  })
+  colls, err := c.GetCollectionTree("", "12@N01")
+  assertOK(t, "GetCollectionTree", err)
+  assertEq(t, "len", 2, len(colls))
+  assertEq(t, "title", "Archive", colls[0].Title)
+  assertEq(t, "iconsmall", "s.jpg", colls[0].IconSmall)
+  assertEq(t, "children", 2, len(colls[0].Collections))
+  assertEq(t, "set", CollectionSet{"101", "January", "cold"},
+    colls[0].Collections[0].Sets[0])
+
+  var visited []string
+  err = WalkCollections(colls, func(path []*Collection, coll *Collection) error {
+    names := []string{}
+    for _, p := range path {
+      names = append(names, p.Title)
+    }
+    visited = append(visited, strings.Join(append(names, coll.Title), "/"))
+    if coll.Title == "2011" {
+      return SkipCollection
+    }
+    return nil
+  })
+  assertOK(t, "walk", err)
+  assertEq(t, "visited", "Archive,Archive/2010,Archive/2011,Misc",
+    strings.Join(visited, ","))
+
+  stop := errors.New("stop")
+  n := 0
+  err = WalkCollections(colls, func(path []*Collection, coll *Collection) error {
+    n++
+    return stop
+  })
+  assertEq(t, "stop", stop, err)
+  assertEq(t, "stopped", 1, n)
+}
+
+func TestGetCollectionInfo(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.collections.getInfo", method)
+    assertEq(t, "collection_id", "12-1", args.Get("collection_id"))
+    return `<rsp stat="ok">
+      <collection id="12-1" child_count="2" datecreate="1173812218"
+          iconlarge="l.jpg" iconsmall="s.jpg" server="" secret="">
+        <title>Archive</title>
+        <description>Everything</description>
+        <iconphotos>
+          <photo id="15" owner="12@N01" secret="7" server="8" farm="1" title="in the kitchen"/>
+        </iconphotos>
+      </collection>
+    </rsp>`
+  })
+  coll, err := c.GetCollectionInfo("12-1")
+  assertOK(t, "GetCollectionInfo", err)
+  assertEq(t, "title", "Archive", coll.Title)
+  assertEq(t, "description", "Everything", coll.Description)
+  assertEq(t, "child_count", 2, coll.ChildCount)
+  assertEq(t, "created", int64(1173812218), coll.CreateTime().Unix())
+  assertEq(t, "iconphotos", 1, len(coll.IconPhotos))
+  assertEq(t, "iconphoto", "in the kitchen", coll.IconPhotos[0].Title)
+}
+
+//-----------------------
+// Tests for photolists.go
+//
+func TestPhotoLists(t *testing.T) {
+  var method string
+  var args url.Values
+  c := newRoutingClient(t, func(m string, a url.Values) string {
+    method, args = m, a
+    page, _ := strconv.Atoi(a.Get("page"))
+    if page == 0 {
+      page = 1
+    }
+    return searchPage(page, 2, "p"+strconv.Itoa(page))
+  })
+  extras := map[string]string{"extras": "date_upload"}
+  lists := []struct {
+    method string
+    user   string
+    get    func() (*SearchResponse, error)
+    all    func() *PhotoIterator
+  }{
+    {"flickr.people.getPhotos", "me",
+      func() (*SearchResponse, error) { return c.GetUserPhotos("me", extras) },
+      func() *PhotoIterator { return c.GetUserPhotosAll("me", extras) }},
+    {"flickr.people.getPublicPhotos", "12@N01",
+      func() (*SearchResponse, error) { return c.GetPublicPhotos("12@N01", extras) },
+      func() *PhotoIterator { return c.GetPublicPhotosAll("12@N01", extras) }},
+    {"flickr.people.getPhotosOf", "12@N01",
+      func() (*SearchResponse, error) { return c.GetPhotosOf("12@N01", extras) },
+      func() *PhotoIterator { return c.GetPhotosOfAll("12@N01", extras) }},
+    {"flickr.photos.getNotInSet", "",
+      func() (*SearchResponse, error) { return c.GetNotInSet(extras) },
+      func() *PhotoIterator { return c.GetNotInSetAll(extras) }},
+    {"flickr.photos.getUntagged", "",
+      func() (*SearchResponse, error) { return c.GetUntagged(extras) },
+      func() *PhotoIterator { return c.GetUntaggedAll(extras) }},
+    {"flickr.photos.getWithGeoData", "",
+      func() (*SearchResponse, error) { return c.GetWithGeoData(extras) },
+      func() *PhotoIterator { return c.GetWithGeoDataAll(extras) }},
+    {"flickr.photos.getWithoutGeoData", "",
+      func() (*SearchResponse, error) { return c.GetWithoutGeoData(extras) },
+      func() *PhotoIterator { return c.GetWithoutGeoDataAll(extras) }},
+  }
+  for _, l := range lists {
+    r, err := l.get()
+    assertOK(t, l.method, err)
+    assertEq(t, "method", l.method, method)
+    assertEq(t, l.method+" user_id", l.user, args.Get("user_id"))
+    assertEq(t, l.method+" extras", "date_upload", args.Get("extras"))
+    assertEq(t, l.method+" photo", "p1", r.Photos[0].ID)
+
+    it := l.all()
+    var ids []string
+    for it.Next() {
+      ids = append(ids, it.Photo().ID)
+    }
+    assertOK(t, l.method+" all", it.Err())
+    assertEq(t, l.method+" all", "p1,p2", strings.Join(ids, ","))
+  }
+  _, ok := extras["user_id"]
+  assert(t, "args unchanged", !ok)
+}
+
+//-----------------------
+// Tests for recent.go
+//
+func TestGetRecent(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.getRecent", method)
+    assertEq(t, "per_page", "5", args.Get("per_page"))
+    return searchPage(1, 1, "1", "2")
+  })
+  r, err := c.GetRecent(map[string]string{"per_page": "5"})
+  assertOK(t, "GetRecent", err)
+  assertEq(t, "len", 2, len(r.Photos))
+}
+
+func TestChangeTracker(t *testing.T) {
+  dir, err := ioutil.TempDir("", "flickgo")
+  assertOK(t, "tempDir", err)
+  defer os.RemoveAll(dir)
+  statePath := filepath.Join(dir, "state")
+
+  // Photo ID -> last update.
+  updates := map[string]int64{"1": 100, "2": 300, "3": 200}
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.recentlyUpdated", method)
+    assertEq(t, "extras", "tags,last_update", args.Get("extras"))
+    minDate, _ := strconv.ParseInt(args.Get("min_date"), 10, 64)
+    photos := ""
+    for _, id := range []string{"1", "2", "3"} {
+      if updates[id] >= minDate {
+        photos += fmt.Sprintf(`<photo id="%s" lastupdate="%d"/>`, id, updates[id])
+      }
+    }
+    return `<rsp stat="ok"><photos page="1" pages="1">` + photos + `</photos></rsp>`
+  })
+  sync := func() string {
+    tr, err := NewChangeTracker(c, statePath)
+    assertOK(t, "NewChangeTracker", err)
+    tr.Args = map[string]string{"extras": "tags"}
+    var ids []string
+    assertOK(t, "sync", tr.Sync(func(p SearchPhoto) error {
+      ids = append(ids, p.ID)
+      return nil
+    }))
+    return strings.Join(ids, ",")
+  }
+
+  assertEq(t, "first sync", "1,2,3", sync())
+  state, _ := ioutil.ReadFile(statePath)
+  assertEq(t, "state", "300\n", string(state))
+  updates["1"] = 400
+  assertEq(t, "second sync", "1,2", sync())
+  assertEq(t, "third sync", "1", sync())
+
+  tr, _ := NewChangeTracker(c, statePath)
+  tr.Args = map[string]string{"extras": "tags"}
+  failure := errors.New("disk full")
+  assertEq(t, "failed sync", failure, tr.Sync(func(SearchPhoto) error {
+    return failure
+  }))
+  assertEq(t, "since unchanged", int64(400), tr.Since.Unix())
+}
+
+//-----------------------
+// Tests for favorites.go
+//
+func TestAddRemoveFavorite(t *testing.T) {
+  c := newExpectClient(t, "flickr.favorites.add", map[string]string{"photo_id": "42"})
+  assertOK(t, "AddFavorite", c.AddFavorite("42"))
+  c = newExpectClient(t, "flickr.favorites.remove", map[string]string{"photo_id": "42"})
+  assertOK(t, "RemoveFavorite", c.RemoveFavorite("42"))
+}
+
+func TestGetFavorites(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.favorites.getList":
+      _, ok := args["user_id"]
+      assert(t, "no user_id", !ok)
+    case "flickr.favorites.getPublicList":
+      assertEq(t, "user_id", "12@N01", args.Get("user_id"))
+    default:
+      t.Errorf("unexpected method %s", method)
+    }
+    assertEq(t, "min_fave_date", "1300000000", args.Get("min_fave_date"))
+    return `<rsp stat="ok"><photos page="1" pages="1" perpage="100" total="1">
+      <photo id="2636" owner="47058503995@N01" secret="a123456" server="2"
+        title="test_04" ispublic="1" isfriend="0" isfamily="0" date_faved="1303347890"/>
+    </photos></rsp>`
+  })
+  args := map[string]string{"min_fave_date": "1300000000"}
+  r, err := c.GetFavorites("", args)
+  assertOK(t, "GetFavorites", err)
+  assertEq(t, "date_faved", int64(1303347890), r.Photos[0].FavedTime().Unix())
+
+  it := c.GetPublicFavoritesAll("12@N01", args)
+  assert(t, "next", it.Next())
+  assertEq(t, "id", "2636", it.Photo().ID)
+  assert(t, "end", !it.Next())
+  assertOK(t, "iterate", it.Err())
+}
+
+func TestGetPhotoFavorites(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.getFavorites", method)
+    assertEq(t, "photo_id", "1253576", args.Get("photo_id"))
+    assertEq(t, "per_page", "2", args.Get("per_page"))
+    return `<rsp stat="ok">
+      <photo id="1253576" secret="81b96be690" server="1" farm="1"
+          page="1" pages="3" perpage="2" total="5">
+        <person nsid="33939862@N00" username="Dementation" favedate="1166689690"/>
+        <person nsid="49485425@N00" username="indigo_jones" favedate="1166573724"/>
+      </photo>
+    </rsp>`
+  })
+  r, err := c.GetPhotoFavorites("1253576", 0, 2)
+  assertOK(t, "GetPhotoFavorites", err)
+  assertEq(t, "pages", 3, r.Pages)
+  assertEq(t, "len", 2, len(r.People))
+  assertEq(t, "username", "indigo_jones", r.People[1].UserName)
+  assertEq(t, "favedate", int64(1166689690), r.People[0].FaveTime().Unix())
+}
+
+func TestGetFavoriteContext(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.favorites.getContext", method)
+    assertEq(t, "user_id", "12@N01", args.Get("user_id"))
+    return `<rsp stat="ok">
+      <prevphoto id="1" secret="s" server="2" farm="3" title="prev"/>
+      <nextphoto id="0"/>
+    </rsp>`
+  })
+  ctx, err := c.GetFavoriteContext("42", "12@N01")
+  assertOK(t, "GetFavoriteContext", err)
+  assertEq(t, "prev", "prev", ctx.Prev.Title)
+  assertEq(t, "next", "0", ctx.Next.ID)
+}
+
+//-----------------------
+// Tests for comments.go
+//
+func TestGetComments(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.comments.getList", method)
+    assertEq(t, "photo_id", "109722179", args.Get("photo_id"))
+    assertEq(t, "min_comment_date", "1141841470", args.Get("min_comment_date"))
+    _, ok := args["max_comment_date"]
+    assert(t, "max_comment_date", !ok)
+    return `<rsp stat="ok">
+      <comments photo_id="109722179">
+        <comment id="6065-109722179-72057594077818641" author="35468159852@N01"
+            authorname="Rev Dan Catt" datecreate="1141841470"
+            permalink="http://www.flickr.com/photos/straup/109722179/#comment72057594077818641"
+            >Umm, I&#39;m not sure, can I get back to you on that &lt;b&gt;one&lt;/b&gt;.</comment>
+      </comments>
+    </rsp>`
+  })
+  comments, err := c.GetComments("109722179", time.Unix(1141841470, 0), time.Time{})
+  assertOK(t, "GetComments", err)
+  assertEq(t, "len", 1, len(comments))
+  cm := comments[0]
+  assertEq(t, "id", "6065-109722179-72057594077818641", cm.ID)
+  assertEq(t, "author", "35468159852@N01", cm.Author)
+  assertEq(t, "authorname", "Rev Dan Catt", cm.AuthorName)
+  assertEq(t, "date", int64(1141841470), cm.CreateTime().Unix())
+  assert(t, "permalink", strings.HasSuffix(cm.Permalink, "#comment72057594077818641"))
+  assertEq(t, "html", "Umm, I'm not sure, can I get back to you on that <b>one</b>.", cm.HTML)
+}
+
+func TestSetComments(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.photosets.comments.getList":
+      assertEq(t, "photoset_id", "1234", args.Get("photoset_id"))
+      return `<rsp stat="ok"><comments photoset_id="1234">
+        <comment id="c1" author="12@N01" datecreate="bad">nice</comment>
+      </comments></rsp>`
+    case "flickr.photosets.comments.addComment":
+      assertEq(t, "comment_text", "nice set", args.Get("comment_text"))
+      return `<rsp stat="ok"><comment id="97777-72057594037941949-72057594037942602"/></rsp>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return `<rsp stat="fail"/>`
+  })
+  comments, err := c.GetSetComments("1234")
+  assertOK(t, "GetSetComments", err)
+  assert(t, "invalid date", comments[0].CreateTime().IsZero())
+  id, err := c.AddSetComment("1234", "nice set")
+  assertOK(t, "AddSetComment", err)
+  assertEq(t, "id", "97777-72057594037941949-72057594037942602", id)
+}
+
+func TestEditComments(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.comments.addComment", method)
+    assertEq(t, "photo_id", "42", args.Get("photo_id"))
+    return `<rsp stat="ok"><comment id="c9"/></rsp>`
+  })
+  id, err := c.AddComment("42", "nice")
+  assertOK(t, "AddComment", err)
+  assertEq(t, "id", "c9", id)
+
+  c = newExpectClient(t, "flickr.photos.comments.editComment",
+    map[string]string{"comment_id": "c9", "comment_text": "nicer"})
+  assertOK(t, "EditComment", c.EditComment("c9", "nicer"))
+  c = newExpectClient(t, "flickr.photos.comments.deleteComment",
+    map[string]string{"comment_id": "c9"})
+  assertOK(t, "DeleteComment", c.DeleteComment("c9"))
+  c = newExpectClient(t, "flickr.photosets.comments.editComment",
+    map[string]string{"comment_id": "c8", "comment_text": "x"})
+  assertOK(t, "EditSetComment", c.EditSetComment("c8", "x"))
+  c = newExpectClient(t, "flickr.photosets.comments.deleteComment",
+    map[string]string{"comment_id": "c8"})
+  assertOK(t, "DeleteSetComment", c.DeleteSetComment("c8"))
+}
+
+func TestGetRecentCommentsForContacts(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.comments.getRecentForContacts", method)
+    assertEq(t, "contacts_filter", "12@N01", args.Get("contacts_filter"))
+    return searchPage(1, 1, "7")
+  })
+  r, err := c.GetRecentCommentsForContacts(map[string]string{"contacts_filter": "12@N01"})
+  assertOK(t, "GetRecentCommentsForContacts", err)
+  assertEq(t, "id", "7", r.Photos[0].ID)
+}
+
+//-----------------------
+// Tests for notes.go
+//
+func TestNotes(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.notes.add", method)
+    assertEq(t, "photo_id", "2733", args.Get("photo_id"))
+    assertEq(t, "note_x", "10", args.Get("note_x"))
+    assertEq(t, "note_y", "20", args.Get("note_y"))
+    assertEq(t, "note_w", "30", args.Get("note_w"))
+    assertEq(t, "note_h", "40", args.Get("note_h"))
+    assertEq(t, "note_text", "castle", args.Get("note_text"))
+    return `<rsp stat="ok"><note id="1234"/></rsp>`
+  })
+  id, err := c.AddNote("2733", Rect{10, 20, 30, 40}, "castle")
+  assertOK(t, "AddNote", err)
+  assertEq(t, "id", "1234", id)
+
+  c = newExpectClient(t, "flickr.photos.notes.edit", map[string]string{
+    "note_id": "1234", "note_x": "1", "note_y": "2", "note_w": "3", "note_h": "4",
+    "note_text": "keep"})
+  assertOK(t, "EditNote", c.EditNote("1234", Rect{1, 2, 3, 4}, "keep"))
+  c = newExpectClient(t, "flickr.photos.notes.delete", map[string]string{"note_id": "1234"})
+  assertOK(t, "DeleteNote", c.DeleteNote("1234"))
+}
+
+func TestPeopleInPhoto(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.people.add", method)
+    _, ok := args["person_x"]
+    assert(t, "person_x", !ok)
+    return `<rsp stat="ok"/>`
+  })
+  assertOK(t, "AddPerson", c.AddPerson("2733", "12@N01", Rect{}))
+
+  c = newExpectClient(t, "flickr.photos.people.editCoords", map[string]string{
+    "photo_id": "2733", "user_id": "12@N01",
+    "person_x": "5", "person_y": "6", "person_w": "7", "person_h": "8"})
+  assertOK(t, "EditPersonCoords", c.EditPersonCoords("2733", "12@N01", Rect{5, 6, 7, 8}))
+  c = newExpectClient(t, "flickr.photos.people.deleteCoords",
+    map[string]string{"photo_id": "2733", "user_id": "12@N01"})
+  assertOK(t, "DeletePersonCoords", c.DeletePersonCoords("2733", "12@N01"))
+  c = newExpectClient(t, "flickr.photos.people.delete",
+    map[string]string{"photo_id": "2733", "user_id": "12@N01"})
+  assertOK(t, "DeletePerson", c.DeletePerson("2733", "12@N01"))
+}
+
+func TestGetPeople(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.people.getList", method)
+    assertEq(t, "photo_id", "2733", args.Get("photo_id"))
+    return `<rsp stat="ok">
+      <people total="2" photo_width="500" photo_height="375">
+        <person nsid="87944415@N00" username="hitherto" iconserver="1" iconfarm="1"
+            realname="Simon Batistoni" added_by="12037949754@N01"
+            x="50" y="50" w="100" h="100"/>
+        <person nsid="12@N01" username="bob" added_by="12@N01"/>
+      </people>
+    </rsp>`
+  })
+  r, err := c.GetPeople("2733")
+  assertOK(t, "GetPeople", err)
+  assertEq(t, "total", 2, r.Total)
+  assertEq(t, "photo_width", 500, r.PhotoWidth)
+  assertEq(t, "len", 2, len(r.People))
+  p := r.People[0]
+  assertEq(t, "nsid", "87944415@N00", p.NSID)
+  assertEq(t, "realname", "Simon Batistoni", p.RealName)
+  assertEq(t, "added_by", "12037949754@N01", p.AddedBy)
+  assertEq(t, "rect", Rect{50, 50, 100, 100}, p.Rect)
+  assert(t, "no rect", r.People[1].IsZero())
+}
+
+//-----------------------
+// Tests for exif.go
+//
+func TestGetExif(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.getExif", method)
+    assertEq(t, "photo_id", "4424", args.Get("photo_id"))
+    assertEq(t, "secret", "06b8e43bc7", args.Get("secret"))
+    return `<rsp stat="ok">
+      <photo id="4424" secret="06b8e43bc7" server="2" farm="1" camera="Canon EOS 5D">
+        <exif tagspace="TIFF" tagspaceid="1" tag="Make" label="Make"><raw>Canon</raw></exif>
+        <exif tagspace="TIFF" tagspaceid="1" tag="Model" label="Model"><raw>Canon EOS 5D</raw></exif>
+        <exif tagspace="ExifIFD" tagspaceid="0" tag="ExposureTime" label="Exposure">
+          <raw>1/60</raw><clean>0.017 sec (1/60)</clean></exif>
+        <exif tagspace="ExifIFD" tagspaceid="0" tag="FNumber" label="Aperture">
+          <raw>5.6</raw><clean>f/5.6</clean></exif>
+        <exif tagspace="ExifIFD" tagspaceid="0" tag="ISO" label="ISO Speed"><raw>400</raw></exif>
+        <exif tagspace="ExifIFD" tagspaceid="0" tag="FocalLength" label="Focal Length">
+          <raw>50.0 mm</raw><clean>50 mm</clean></exif>
+        <exif tagspace="ExifIFD" tagspaceid="0" tag="LensModel" label="Lens Model">
+          <raw>EF50mm f/1.4 USM</raw></exif>
+        <exif tagspace="GPS" tagspaceid="0" tag="GPSLatitudeRef" label="GPS Latitude Ref"><raw>North</raw></exif>
+        <exif tagspace="GPS" tagspaceid="0" tag="GPSLatitude" label="GPS Latitude">
+          <raw>51 deg 30' 36.00"</raw></exif>
+        <exif tagspace="GPS" tagspaceid="0" tag="GPSLongitudeRef" label="GPS Longitude Ref"><raw>West</raw></exif>
+        <exif tagspace="GPS" tagspaceid="0" tag="GPSLongitude" label="GPS Longitude">
+          <raw>0 deg 7' 30.00"</raw></exif>
+      </photo>
+    </rsp>`
+  })
+  r, err := c.GetExif("4424", "06b8e43bc7")
+  assertOK(t, "GetExif", err)
+  assertEq(t, "camera", "Canon EOS 5D", r.Camera)
+  assertEq(t, "len", 11, len(r.Tags))
+  assertEq(t, "tagspace", "TIFF", r.Tags[0].TagSpace)
+  assertEq(t, "label", "Exposure", r.Tags[2].Label)
+  assertEq(t, "raw", "1/60", r.Tags[2].Raw)
+  assertEq(t, "make", "Canon", r.Make())
+  assertEq(t, "model", "Canon EOS 5D", r.Model())
+  assertEq(t, "lens", "EF50mm f/1.4 USM", r.Lens())
+  assertEq(t, "exposure", "0.017 sec (1/60)", r.Exposure())
+  assertEq(t, "aperture", "f/5.6", r.Aperture())
+  assertEq(t, "iso", "400", r.ISO())
+  assertEq(t, "focal length", "50 mm", r.FocalLength())
+  lat, lon, ok := r.GPS()
+  assert(t, "gps ok", ok)
+  assertEq(t, "lat", 51.51, lat)
+  assertEq(t, "lon", -0.125, lon)
+}
+
+func TestExifMissingGPS(t *testing.T) {
+  r := &ExifResponse{Tags: []ExifTag{{Tag: "GPSLatitude", Raw: "12.5"}}}
+  _, _, ok := r.GPS()
+  assert(t, "gps ok", !ok)
+  assertEq(t, "make", "", r.Make())
+}
+
+func TestCameras(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.cameras.getBrands":
+      return `<rsp stat="ok"><brands>
+        <brand id="canon" name="Canon"/><brand id="nikon" name="Nikon"/>
+      </brands></rsp>`
+    case "flickr.cameras.getBrandModels":
+      assertEq(t, "brand", "canon", args.Get("brand"))
+      return `<rsp stat="ok"><cameras brand="canon">
+        <camera id="eos_5d">
+          <name>Canon EOS 5D</name>
+          <details><megapixels>12.8</megapixels><lcd_screen_size>2.5</lcd_screen_size>
+            <memory_type>Compact Flash</memory_type></details>
+          <images><small>http://example.com/s.jpg</small><large>http://example.com/l.jpg</large></images>
+        </camera>
+      </cameras></rsp>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return ""
+  })
+  brands, err := c.GetCameraBrands()
+  assertOK(t, "GetCameraBrands", err)
+  assertEq(t, "len", 2, len(brands))
+  assertEq(t, "name", "Nikon", brands[1].Name)
+  cameras, err := c.GetBrandModels("canon")
+  assertOK(t, "GetBrandModels", err)
+  assertEq(t, "len", 1, len(cameras))
+  assertEq(t, "name", "Canon EOS 5D", cameras[0].Name)
+  assertEq(t, "megapixels", 12.8, cameras[0].MegaPixels)
+  assertEq(t, "memory", "Compact Flash", cameras[0].MemoryType)
+  assertEq(t, "large", "http://example.com/l.jpg", cameras[0].LargeImage)
+}
+
+//-----------------------
+// Tests for geo.go
+//
+func TestGetLocation(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.geo.getLocation", method)
+    assertEq(t, "photo_id", "123", args.Get("photo_id"))
+    return `<rsp stat="ok">
+      <photo id="123">
+        <location latitude="-17.685895" longitude="-63.36914" accuracy="6" context="0"
+            place_id="7yYXXpOcBJ9.1Ck" woeid="332471">
+          <neighbourhood place_id="n1" woeid="1">Centro</neighbourhood>
+          <locality place_id="l1" woeid="2">Santa Cruz</locality>
+          <county place_id="c1" woeid="3">Andres Ibanez</county>
+          <region place_id="r1" woeid="4">Santa Cruz</region>
+          <country place_id="b1" woeid="5">Bolivia</country>
+        </location>
+      </photo>
+    </rsp>`
+  })
+  l, err := c.GetLocation("123")
+  assertOK(t, "GetLocation", err)
+  assertEq(t, "lat", -17.685895, l.Latitude)
+  assertEq(t, "lon", -63.36914, l.Longitude)
+  assertEq(t, "accuracy", AccuracyRegion, l.Accuracy)
+  assertEq(t, "context", GeoContextNone, l.Context)
+  assertEq(t, "woeid", "332471", l.WOEID)
+  assertEq(t, "neighbourhood", "n1", l.Neighbourhood.PlaceID)
+  assertEq(t, "locality", "Santa Cruz", l.Locality.Name)
+  assertEq(t, "county", "3", l.County.WOEID)
+  assertEq(t, "region", "r1", l.Region.PlaceID)
+  assertEq(t, "country", "Bolivia", l.Country.Name)
+}
+
+func TestSetLocation(t *testing.T) {
+  c := newExpectClient(t, "flickr.photos.geo.setLocation", map[string]string{
+    "photo_id": "123", "lat": "48.8583", "lon": "2.2945", "accuracy": "16", "context": "2"})
+  assertOK(t, "SetLocation",
+    c.SetLocation("123", 48.8583, 2.2945, AccuracyStreet, GeoContextOutdoors))
+
+  c = newRoutingClient(t, func(method string, args url.Values) string {
+    _, ok := args["accuracy"]
+    assert(t, "accuracy", !ok)
+    _, ok = args["context"]
+    assert(t, "context", !ok)
+    return `<rsp stat="ok"/>`
+  })
+  assertOK(t, "SetLocation", c.SetLocation("123", -1.5, 0, 0, GeoContextNone))
+
+  c = newExpectClient(t, "flickr.photos.geo.removeLocation", map[string]string{"photo_id": "123"})
+  assertOK(t, "RemoveLocation", c.RemoveLocation("123"))
+  c = newExpectClient(t, "flickr.photos.geo.setContext",
+    map[string]string{"photo_id": "123", "context": "1"})
+  assertOK(t, "SetGeoContext", c.SetGeoContext("123", GeoContextIndoors))
+  c = newExpectClient(t, "flickr.photos.geo.batchCorrectLocation", map[string]string{
+    "lat": "10", "lon": "20", "accuracy": "11", "place_id": "kH8dLOubBZRvX_YZ"})
+  assertOK(t, "BatchCorrectLocation",
+    c.BatchCorrectLocation(10, 20, AccuracyCity, "kH8dLOubBZRvX_YZ", ""))
+}
+
+func TestGeoPerms(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.geo.getPerms", method)
+    return `<rsp stat="ok">
+      <perms id="10592" ispublic="0" iscontact="0" isfriend="0" isfamily="1"/>
+    </rsp>`
+  })
+  p, err := c.GetGeoPerms("10592")
+  assertOK(t, "GetGeoPerms", err)
+  assertEq(t, "perms", GeoPerms{IsFamily: true}, *p)
+
+  c = newExpectClient(t, "flickr.photos.geo.setPerms", map[string]string{
+    "photo_id": "10592", "is_public": "1", "is_contact": "0", "is_friend": "1", "is_family": "0"})
+  assertOK(t, "SetGeoPerms", c.SetGeoPerms("10592", GeoPerms{IsPublic: true, IsFriend: true}))
+}
+
+func TestPhotosForLocation(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.photos.geo.photosForLocation", method)
+    assertEq(t, "lat", "1.25", args.Get("lat"))
+    assertEq(t, "lon", "-3", args.Get("lon"))
+    assertEq(t, "accuracy", "16", args.Get("accuracy"))
+    assertEq(t, "extras", "geo", args.Get("extras"))
+    return searchPage(1, 1, "7", "8")
+  })
+  r, err := c.PhotosForLocation(1.25, -3, AccuracyStreet, map[string]string{"extras": "geo"})
+  assertOK(t, "PhotosForLocation", err)
+  assertEq(t, "len", 2, len(r.Photos))
+}
+
+//-----------------------
+// Tests for gpx.go
+//
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="logger" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><name>walk</name>
+    <trkseg>
+      <trkpt lat="10.0" lon="20.0"><ele>100</ele><time>2013-06-01T10:00:00Z</time></trkpt>
+      <trkpt lat="11.0" lon="22.0"><ele>200</ele><time>2013-06-01T10:02:00Z</time></trkpt>
+      <trkpt lat="12.0" lon="24.0"><ele>300</ele></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="30.0" lon="40.0"><time>2013-06-01T12:00:00Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestParseGPX(t *testing.T) {
+  track, err := ParseGPX(strings.NewReader(testGPX))
+  assertOK(t, "ParseGPX", err)
+  assertEq(t, "len", 3, len(track))
+  assertEq(t, "lat", 11.0, track[1].Lat)
+  assertEq(t, "ele", 200.0, track[1].Ele)
+  assertEq(t, "time", int64(1370080920), track[1].Time.Unix())
+
+  _, err = ParseGPX(strings.NewReader(`<gpx><trk><trkseg><trkpt><time>noon</time></trkpt></trkseg></trk></gpx>`))
+  assert(t, "bad time", err != nil)
+}
+
+func TestTrackLocate(t *testing.T) {
+  track, _ := ParseGPX(strings.NewReader(testGPX))
+  start := track[0].Time
+
+  p, ok := track.Locate(start.Add(time.Minute), 5*time.Minute)
+  assert(t, "interpolated", ok)
+  assertEq(t, "lat", 10.5, p.Lat)
+  assertEq(t, "lon", 21.0, p.Lon)
+  assertEq(t, "ele", 150.0, p.Ele)
+
+  p, ok = track.Locate(start, 0)
+  assert(t, "exact", ok)
+  assertEq(t, "exact lat", 10.0, p.Lat)
+
+  _, ok = track.Locate(start.Add(-6*time.Minute), 5*time.Minute)
+  assert(t, "before track", !ok)
+  p, ok = track.Locate(start.Add(-4*time.Minute), 5*time.Minute)
+  assert(t, "near start", ok)
+  assertEq(t, "near start lat", 10.0, p.Lat)
+
+  // In the gap between 10:02 and 12:00, only points near either end match.
+  p, ok = track.Locate(start.Add(5*time.Minute), 5*time.Minute)
+  assert(t, "gap start", ok)
+  assertEq(t, "gap start lat", 11.0, p.Lat)
+  _, ok = track.Locate(start.Add(time.Hour), 5*time.Minute)
+  assert(t, "gap middle", !ok)
+  p, ok = track.Locate(start.Add(119*time.Minute), 5*time.Minute)
+  assert(t, "gap end", ok)
+  assertEq(t, "gap end lat", 30.0, p.Lat)
+}
+
+func TestGeotagger(t *testing.T) {
+  var set []string
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    id := args.Get("photo_id")
+    switch method {
+    case "flickr.photos.getInfo":
+      taken := map[string]string{
+        "1": "2013-06-01 12:01:30", // 10:01 UTC once the clock is corrected
+        "2": "2013-06-01 15:00:00",
+        "3": "2013-06-01 12:01:00",
+        "4": "bad",
+      }[id]
+      location := ""
+      if id == "3" {
+        location = `<location latitude="1" longitude="2"/>`
+      }
+      return `<rsp stat="ok"><photo id="` + id + `">
+        <dates taken="` + taken + `"/>` + location + `</photo></rsp>`
+    case "flickr.photos.geo.setLocation":
+      set = append(set, id+"@"+args.Get("lat")+","+args.Get("lon")+"/"+args.Get("accuracy"))
+      return `<rsp stat="ok"/>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return ""
+  })
+  track, _ := ParseGPX(strings.NewReader(testGPX))
+  g := NewGeotagger(c, track)
+  g.TimeZone = time.FixedZone("CEST", 2*60*60)
+  g.ClockOffset = 30 * time.Second
+  g.DryRun = true
+
+  ids := []string{"1", "2", "3", "4"}
+  report, err := g.Run(ids)
+  assertOK(t, "dry run", err)
+  assertEq(t, "dry run set", 0, len(set))
+  assertEq(t, "matched", 1, report.Matched)
+  assertEq(t, "unmatched", 1, report.Unmatched)
+  assertEq(t, "skipped", 1, report.Skipped)
+  assertEq(t, "failed", 1, report.Failed)
+  r := report.Results[0]
+  assertEq(t, "taken", int64(1370080860), r.Taken.Unix())
+  assertEq(t, "lat", 10.5, r.Point.Lat)
+  assert(t, "applied", !r.Applied)
+  assert(t, "report", strings.HasPrefix(report.String(),
+    "1: taken 2013-06-01T12:01:00+02:00, at 10.500000,21.000000\n2: taken "))
+
+  g.DryRun = false
+  report, err = g.Run(ids)
+  assertOK(t, "run", err)
+  assert(t, "applied", report.Results[0].Applied)
+  assertEq(t, "set", "1@10.5,21/16", strings.Join(set, " "))
+
+  _, err = NewGeotagger(c, nil).Run(ids)
+  assert(t, "empty track", err != nil)
+}
+
+//-----------------------
+// Tests for places.go
+//
+func TestFindPlaces(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.places.find":
+      assertEq(t, "query", "Alabama", args.Get("query"))
+      return `<rsp stat="ok"><places query="Alabama" total="1">
+        <place place_id="VrrjuESbApjeFS4." woeid="2347559" latitude="32.614"
+            longitude="-86.680" place_url="/United+States/Alabama"
+            place_type="region" place_type_id="8" timezone="America/Chicago">
+          Alabama, US, United States</place>
+      </places></rsp>`
+    case "flickr.places.findByLatLon":
+      assertEq(t, "lat", "37.76513627957266", args.Get("lat"))
+      assertEq(t, "accuracy", "16", args.Get("accuracy"))
+      return `<rsp stat="ok"><places latitude="37.76513627957266" longitude="-122.42020770907402"
+          accuracy="16" total="1">
+        <place place_id="Y12JWsKbApmnSQpbQg" woeid="23512048" latitude="37.765"
+            longitude="-122.424" place_url="/United+States/California/San+Francisco/Mission+Dolores"
+            place_type="neighbourhood" place_type_id="22" timezone="America/Los_Angeles"
+            name="Mission Dolores, San Francisco, CA, US, United States"/>
+      </places></rsp>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return ""
+  })
+  places, err := c.FindPlaces("Alabama")
+  assertOK(t, "FindPlaces", err)
+  assertEq(t, "len", 1, len(places))
+  p := places[0]
+  assertEq(t, "place_id", "VrrjuESbApjeFS4.", p.PlaceID)
+  assertEq(t, "woeid", "2347559", p.WOEID)
+  assertEq(t, "lat", 32.614, p.Latitude)
+  assertEq(t, "type", "region", p.PlaceType)
+  assertEq(t, "type id", PlaceRegion, p.PlaceTypeID)
+  assertEq(t, "name", "Alabama, US, United States", p.Name)
+
+  places, err = c.FindPlacesByLatLon(37.76513627957266, -122.42020770907402, AccuracyStreet)
+  assertOK(t, "FindPlacesByLatLon", err)
+  assertEq(t, "name", "Mission Dolores, San Francisco, CA, US, United States", places[0].Name)
+  assertEq(t, "type id", PlaceNeighbourhood, places[0].PlaceTypeID)
+
+  args := map[string]string{}
+  SetPlaceSearch(args, &places[0])
+  assertEq(t, "place_id", "Y12JWsKbApmnSQpbQg", args["place_id"])
+  args = map[string]string{}
+  SetPlaceSearch(args, &Place{WOEID: "2347559"})
+  assertEq(t, "woe_id", "2347559", args["woe_id"])
+}
+
+func TestGetPlaceInfo(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    if method == "flickr.places.getInfoByUrl" {
+      assertEq(t, "url", "/Canada/Quebec/Montreal", args.Get("url"))
+    } else {
+      assertEq(t, "method", "flickr.places.getInfo", method)
+      assertEq(t, "woe_id", "23512048", args.Get("woe_id"))
+      _, ok := args["place_id"]
+      assert(t, "place_id", !ok)
+    }
+    return `<rsp stat="ok">
+      <place place_id="Y12JWsKbApmnSQpbQg" woeid="23512048" latitude="37.765"
+          longitude="-122.424" place_url="/United+States/California/San+Francisco/Mission+Dolores"
+          place_type="neighbourhood" place_type_id="22" has_shapedata="1"
+          timezone="America/Los_Angeles" name="Mission Dolores, San Francisco, CA, US, United States">
+        <locality place_id="kH8dLOubBZRvX_YZ" woeid="2487956" latitude="37.779"
+            longitude="-122.420" place_url="/United+States/California/San+Francisco">San Francisco, California</locality>
+        <county place_id="hCca8XSYA5nn0X1Sfw" woeid="12587707" latitude="37.759"
+            longitude="-122.435" place_url="/hCca8XSYA5nn0X1Sfw">San Francisco County, California</county>
+        <region place_id="SVrAMtCbAphCLAtP" woeid="2347563" latitude="37.271"
+            longitude="-119.270" place_url="/United+States/California">California</region>
+        <country place_id="4KO02SibApitvSBieQ" woeid="23424977" latitude="48.890"
+            longitude="-116.982" place_url="/United+States">United States</country>
+        <shapedata created="1223513357" alpha="0.00015" count_points="1185" count_edges="55" is_donuthole="0">
+          <polylines>
+            <polyline>37.762,-122.428 37.762,-122.425 bad 37.761,-122.421</polyline>
+          </polylines>
+          <urls><shapefile>http://farm4.static.flickr.com/3228/shapefiles/23512048_20081008_e8bcb3.tar.gz</shapefile></urls>
+        </shapedata>
+      </place>
+    </rsp>`
+  })
+  p, err := c.GetPlaceInfo("", "23512048")
+  assertOK(t, "GetPlaceInfo", err)
+  assertEq(t, "timezone", "America/Los_Angeles", p.Timezone)
+  assertEq(t, "locality", "San Francisco, California", p.Locality.Name)
+  assertEq(t, "locality lat", 37.779, p.Locality.Latitude)
+  assertEq(t, "region url", "/United+States/California", p.Region.PlaceURL)
+  assertEq(t, "country", "23424977", p.Country.WOEID)
+  assertEq(t, "neighbourhood", "", p.Neighbourhood.PlaceID)
+  assert(t, "has shapedata", p.HasShapeData)
+  assertEq(t, "created", int64(1223513357), p.ShapeData.CreateTime().Unix())
+  assertEq(t, "points", 1185, p.ShapeData.Points)
+  assertEq(t, "edges", 55, p.ShapeData.Edges)
+  assert(t, "shapefile", strings.HasSuffix(p.ShapeData.ShapeFile, ".tar.gz"))
+  coords := p.ShapeData.Coordinates()
+  assertEq(t, "polylines", 1, len(coords))
+  assertEq(t, "pairs", 3, len(coords[0]))
+  assertEq(t, "pair", [2]float64{37.761, -122.421}, coords[0][2])
+
+  p, err = c.GetPlaceInfoByURL("/Canada/Quebec/Montreal")
+  assertOK(t, "GetPlaceInfoByURL", err)
+  assertEq(t, "woeid", "23512048", p.WOEID)
+}
+
+func TestPlaceLists(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.places.getChildrenWithPhotosPublic":
+      assertEq(t, "place_id", "4KO02SibApitvSBieQ", args.Get("place_id"))
+    case "flickr.places.placesForUser":
+      assertEq(t, "place_type_id", "7", args.Get("place_type_id"))
+      assertEq(t, "threshold", "5", args.Get("threshold"))
+    case "flickr.places.placesForBoundingBox":
+      assertEq(t, "bbox", "-122.42307100000001,37.773779,-122.381071,37.815779", args.Get("bbox"))
+      assertEq(t, "place_type_id", "22", args.Get("place_type_id"))
+    case "flickr.places.getTopPlacesList":
+      assertEq(t, "place_type_id", "12", args.Get("place_type_id"))
+      assertEq(t, "date", "2013-06-01", args.Get("date"))
+    default:
+      t.Errorf("unexpected method %s", method)
+    }
+    return `<rsp stat="ok"><places total="1">
+      <place place_id="kH8dLOubBZRvX_YZ" woeid="2487956" latitude="37.779"
+          longitude="-122.420" place_url="/United+States/California/San+Francisco"
+          place_type="locality" place_type_id="7" photo_count="156">San Francisco, California</place>
+    </places></rsp>`
+  })
+  check := func(name string, places []Place, err error) {
+    assertOK(t, name, err)
+    assertEq(t, name+" len", 1, len(places))
+    assertEq(t, name+" photo_count", 156, places[0].PhotoCount)
+    assertEq(t, name+" name", "San Francisco, California", places[0].Name)
+  }
+  places, err := c.GetPlaceChildrenWithPhotos("4KO02SibApitvSBieQ", "")
+  check("GetPlaceChildrenWithPhotos", places, err)
+  places, err = c.PlacesForUser(PlaceLocality, map[string]string{"threshold": "5"})
+  check("PlacesForUser", places, err)
+  places, err = c.PlacesForBoundingBox(-122.42307100000001, 37.773779, -122.381071, 37.815779,
+    PlaceNeighbourhood)
+  check("PlacesForBoundingBox", places, err)
+  places, err = c.GetTopPlaces(PlaceCountry, map[string]string{"date": "2013-06-01"})
+  check("GetTopPlaces", places, err)
+}
+
+func TestGetTagsForPlace(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.places.tagsForPlace", method)
+    assertEq(t, "woe_id", "2487956", args.Get("woe_id"))
+    assertEq(t, "min_taken_date", "2013-01-01", args.Get("min_taken_date"))
+    return `<rsp stat="ok"><tags total="2">
+      <tag count="31">fish</tag><tag count="27">bridge</tag>
+    </tags></rsp>`
+  })
+  tags, err := c.GetTagsForPlace("", "2487956", map[string]string{"min_taken_date": "2013-01-01"})
+  assertOK(t, "GetTagsForPlace", err)
+  assertEq(t, "len", 2, len(tags))
+  assertEq(t, "tag", PlaceTag{31, "fish"}, tags[0])
+  assertEq(t, "tag", PlaceTag{27, "bridge"}, tags[1])
+}
+
+//-----------------------
+// Tests for export.go
+//
+func geoPhotos() []SearchPhoto {
+  ps := make([]SearchPhoto, 3)
+  for i := range ps {
+    ps[i].ID = strconv.Itoa(i + 1)
+    ps[i].Owner = "12@N01"
+    ps[i].Secret = "abc"
+    ps[i].Server = "7"
+    ps[i].Farm = "1"
+  }
+  ps[0].Title = `Fish & "chips"`
+  ps[0].Latitude, ps[0].Longitude, ps[0].Accuracy = 51.5, -0.125, 16
+  ps[2].Latitude, ps[2].Longitude, ps[2].Accuracy = -33.8, 151.2, 11
+  return ps
+}
+
+func TestGeoJSONWriter(t *testing.T) {
+  var b bytes.Buffer
+  g := NewGeoJSONWriter(&b)
+  for _, p := range geoPhotos() {
+    assertOK(t, "Write", g.Write(&p))
+  }
+  assertOK(t, "Close", g.Close())
+  assertOK(t, "Close again", g.Close())
+
+  var fc struct {
+    Type     string `json:"type"`
+    Features []struct {
+      ID       string `json:"id"`
+      Geometry struct {
+        Type        string    `json:"type"`
+        Coordinates []float64 `json:"coordinates"`
+      } `json:"geometry"`
+      Properties map[string]string `json:"properties"`
+    } `json:"features"`
+  }
+  assertOK(t, "Unmarshal", json.Unmarshal(b.Bytes(), &fc))
+  assertEq(t, "type", "FeatureCollection", fc.Type)
+  assertEq(t, "len", 2, len(fc.Features))
+  f := fc.Features[0]
+  assertEq(t, "id", "1", f.ID)
+  assertEq(t, "geometry", "Point", f.Geometry.Type)
+  assertEq(t, "lon", -0.125, f.Geometry.Coordinates[0])
+  assertEq(t, "lat", 51.5, f.Geometry.Coordinates[1])
+  assertEq(t, "title", `Fish & "chips"`, f.Properties["title"])
+  assertEq(t, "url", "http://www.flickr.com/photos/12@N01/1/", f.Properties["url"])
+  assertEq(t, "thumbnail", "http://farm1.static.flickr.com/7/1_abc_t.jpg", f.Properties["thumbnail"])
+  assertEq(t, "untitled", "3", fc.Features[1].Properties["title"])
+
+  b.Reset()
+  assertOK(t, "Close empty", NewGeoJSONWriter(&b).Close())
+  assertOK(t, "Unmarshal empty", json.Unmarshal(b.Bytes(), &fc))
+  assertEq(t, "empty len", 0, len(fc.Features))
+}
+
+func TestKMLWriter(t *testing.T) {
+  var b bytes.Buffer
+  k := NewKMLWriter(&b, "Trip")
+  k.ThumbSize = SizeSmallSquare
+  for _, p := range geoPhotos() {
+    assertOK(t, "Write", k.Write(&p))
+  }
+  assertOK(t, "Close", k.Close())
+
+  var doc struct {
+    Name       string `xml:"Document>name"`
+    Placemarks []struct {
+      ID          string `xml:"id,attr"`
+      Name        string `xml:"name"`
+      Description string `xml:"description"`
+      Coordinates string `xml:"Point>coordinates"`
+    } `xml:"Document>Placemark"`
+  }
+  assertOK(t, "Unmarshal", xml.Unmarshal(b.Bytes(), &doc))
+  assertEq(t, "name", "Trip", doc.Name)
+  assertEq(t, "len", 2, len(doc.Placemarks))
+  pm := doc.Placemarks[0]
+  assertEq(t, "id", "photo1", pm.ID)
+  assertEq(t, "title", `Fish & "chips"`, pm.Name)
+  assertEq(t, "coordinates", "-0.125,51.5", pm.Coordinates)
+  assertEq(t, "description", `<a href="http://www.flickr.com/photos/12@N01/1/">`+
+    `<img src="http://farm1.static.flickr.com/7/1_abc_s.jpg" alt="Fish &amp; &#34;chips&#34;"/></a>`,
+    pm.Description)
+}
+
+func TestExportPhotos(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "extras", "geo", args.Get("extras"))
+    return `<rsp stat="ok"><photos page="1" pages="1" perpage="100" total="2">
+      <photo id="1" owner="12@N01" secret="abc" server="7" farm="1" title="a"
+          latitude="51.5" longitude="-0.125" accuracy="16" context="0"/>
+      <photo id="2" owner="12@N01" secret="abc" server="7" farm="1" title="b"
+          latitude="0" longitude="0" accuracy="0"/>
+    </photos></rsp>`
+  })
+  var b bytes.Buffer
+  n, err := ExportPhotos(NewKMLWriter(&b, "All"), c.GetWithGeoDataAll(map[string]string{"extras": "geo"}))
+  assertOK(t, "ExportPhotos", err)
+  assertEq(t, "n", 1, n)
+  assert(t, "placemark", strings.Contains(b.String(), "<coordinates>-0.125,51.5</coordinates>"))
+  assert(t, "closed", strings.HasSuffix(b.String(), "</Document></kml>\n"))
+
+  // A failed listing still ends the document, and its error is kept.
+  c = newRoutingClient(t, func(method string, args url.Values) string {
+    return `<rsp stat="fail"><err code="105" msg="Service currently unavailable"/></rsp>`
+  })
+  b.Reset()
+  n, err = ExportPhotos(NewGeoJSONWriter(&b), c.GetWithGeoDataAll(nil))
+  assert(t, "listing error", strings.HasPrefix(fmt.Sprint(err), "listing photos failed"))
+  assertEq(t, "failed n", 0, n)
+  assertEq(t, "empty", `{"type":"FeatureCollection","features":[]}`+"\n", b.String())
+}
+
+//-----------------------
+// Tests for groups.go
+//
+func TestSearchGroups(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.groups.search", method)
+    assertEq(t, "text", "castles", args.Get("text"))
+    assertEq(t, "page", "2", args.Get("page"))
+    return `<rsp stat="ok"><groups page="2" pages="3" perpage="10" total="25">
+      <group nsid="3000@N02" name="Castles" eighteenplus="0" members="1200"
+          pool_count="4500" topic_count="12" iconserver="1" iconfarm="1"/>
+    </groups></rsp>`
+  })
+  r, err := c.SearchGroups("castles", 2, 10)
+  assertOK(t, "SearchGroups", err)
+  assertEq(t, "total", 25, r.Total)
+  g := r.Groups[0]
+  assertEq(t, "nsid", "3000@N02", g.NSID)
+  assertEq(t, "name", "Castles", g.Name)
+  assertEq(t, "members", 1200, g.Members)
+  assertEq(t, "pool_count", 4500, g.PoolCount)
+  assertEq(t, "topic_count", 12, g.TopicCount)
+}
+
+func TestGetGroupInfo(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.groups.getInfo", method)
+    assertEq(t, "group_id", "34427465497@N01", args.Get("group_id"))
+    return `<rsp stat="ok">
+      <group id="34427465497@N01" iconserver="1" iconfarm="1" lang="en-us"
+          ispoolmoderated="1" path_alias="gne">
+        <name>GNEverybody</name>
+        <description>The group for GNE players</description>
+        <members>69</members>
+        <pool_count>150</pool_count>
+        <topic_count>3</topic_count>
+        <privacy>3</privacy>
+        <throttle count="10" mode="month" remaining="3"/>
+      </group>
+    </rsp>`
+  })
+  g, err := c.GetGroupInfo("34427465497@N01")
+  assertOK(t, "GetGroupInfo", err)
+  assertEq(t, "nsid", "34427465497@N01", g.NSID)
+  assertEq(t, "name", "GNEverybody", g.Name)
+  assertEq(t, "description", "The group for GNE players", g.Description)
+  assertEq(t, "members", 69, g.Members)
+  assertEq(t, "pool_count", 150, g.PoolCount)
+  assertEq(t, "privacy", GroupPublic, g.Privacy)
+  assertEq(t, "path_alias", "gne", g.PathAlias)
+  assert(t, "moderated", g.IsPoolModerated)
+  assertEq(t, "throttle", Throttle{10, "month", 3}, g.Throttle)
+}
+
+func TestThrottleCanAdd(t *testing.T) {
+  assert(t, "none", (&Throttle{Mode: "none"}).CanAdd())
+  assert(t, "missing", (&Throttle{}).CanAdd())
+  assert(t, "disabled", !(&Throttle{Mode: "disabled"}).CanAdd())
+  assert(t, "remaining", (&Throttle{Count: 1, Mode: "day", Remaining: 1}).CanAdd())
+  assert(t, "used up", !(&Throttle{Count: 1, Mode: "day"}).CanAdd())
+}
+
+func TestJoinLeaveGroup(t *testing.T) {
+  c := newExpectClient(t, "flickr.groups.join",
+    map[string]string{"group_id": "3000@N02", "accept_rules": "1"})
+  assertOK(t, "JoinGroup", c.JoinGroup("3000@N02", true))
+  c = newExpectClient(t, "flickr.groups.leave", map[string]string{"group_id": "3000@N02"})
+  assertOK(t, "LeaveGroup", c.LeaveGroup("3000@N02", false))
+}
+
+func TestPools(t *testing.T) {
+  var added []string
+  remaining := "1"
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.groups.getInfo":
+      return `<rsp stat="ok"><group id="3000@N02"><name>Castles</name>
+        <throttle count="1" mode="day" remaining="` + remaining + `"/></group></rsp>`
+    case "flickr.groups.pools.add":
+      assertEq(t, "group_id", "3000@N02", args.Get("group_id"))
+      id := args.Get("photo_id")
+      added = append(added, id)
+      switch id {
+      case "2":
+        return `<rsp stat="fail"><err code="3" msg="Photo already in pool"/></rsp>`
+      case "3":
+        return `<rsp stat="fail"><err code="6" msg="Photo added to pending queue"/></rsp>`
+      case "4":
+        return `<rsp stat="fail"><err code="5" msg="Photo limit reached"/></rsp>`
+      }
+      return `<rsp stat="ok"/>`
+    case "flickr.groups.pools.remove":
+      assertEq(t, "photo_id", "1", args.Get("photo_id"))
+      return `<rsp stat="ok"/>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return ""
+  })
+  pending, err := c.AddToPool("1", "3000@N02")
+  assertOK(t, "added", err)
+  assert(t, "added pending", !pending)
+  pending, err = c.AddToPool("2", "3000@N02")
+  assertOK(t, "already in pool", err)
+  pending, err = c.AddToPool("3", "3000@N02")
+  assertOK(t, "pending", err)
+  assert(t, "pending", pending)
+  _, err = c.AddToPool("4", "3000@N02")
+  assertEq(t, "limit", "Flickr error code 5: Photo limit reached", err.Error())
+
+  _, err = c.SubmitToPool("5", "3000@N02")
+  assertOK(t, "SubmitToPool", err)
+  remaining = "0"
+  _, err = c.SubmitToPool("6", "3000@N02")
+  assertEq(t, "throttled", ErrPoolThrottled, err)
+  assertEq(t, "added", "1,2,3,4,5", strings.Join(added, ","))
+
+  assertOK(t, "RemoveFromPool", c.RemoveFromPool("1", "3000@N02"))
+}
+
+func TestPoolListings(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.groups.pools.getPhotos":
+      assertEq(t, "group_id", "3000@N02", args.Get("group_id"))
+      return searchPage(1, 1, "1", "2")
+    case "flickr.groups.pools.getContext":
+      return `<rsp stat="ok">
+        <prevphoto id="1" secret="a" server="1" farm="1" title="one" url="/photos/x/1/" thumb="t1"/>
+        <nextphoto id="0"/>
+      </rsp>`
+    case "flickr.groups.pools.getGroups":
+      return `<rsp stat="ok"><groups page="1" pages="1" per_page="400" total="1">
+        <group nsid="3000@N02" name="Castles" admin="1" privacy="2" photos="45" iconserver="1" iconfarm="1"/>
+      </groups></rsp>`
+    case "flickr.people.getGroups":
+      assertEq(t, "user_id", "12@N01", args.Get("user_id"))
+      return `<rsp stat="ok"><groups>
+        <group nsid="3000@N02" name="Castles" admin="0" eighteenplus="0"
+            invitation_only="1" members="1200" pool_count="4500"/>
+      </groups></rsp>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return ""
+  })
+  n := 0
+  it := c.GetPoolPhotosAll("3000@N02", nil)
+  for it.Next() {
+    n++
+  }
+  assertOK(t, "GetPoolPhotosAll", it.Err())
+  assertEq(t, "photos", 2, n)
+
+  ctx, err := c.GetPoolContext("2", "3000@N02")
+  assertOK(t, "GetPoolContext", err)
+  assertEq(t, "prev", "1", ctx.Prev.ID)
+
+  pg, err := c.GetPoolGroups(0, 0)
+  assertOK(t, "GetPoolGroups", err)
+  assertEq(t, "len", 1, len(pg.Groups))
+  assert(t, "admin", pg.Groups[0].IsAdmin)
+  assertEq(t, "privacy", GroupInviteOnly, pg.Groups[0].Privacy)
+  assertEq(t, "pool count", 45, pg.Groups[0].PoolCount)
+
+  groups, err := c.GetUserGroups("12@N01")
+  assertOK(t, "GetUserGroups", err)
+  assertEq(t, "len", 1, len(groups))
+  assert(t, "invitation only", groups[0].InvitationOnly)
+  assertEq(t, "members", 1200, groups[0].Members)
+}
+
+//-----------------------
+// Tests for discuss.go
+//
+func TestGetTopics(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.groups.discuss.topics.getList":
+      assertEq(t, "group_id", "46744914@N00", args.Get("group_id"))
+      assertEq(t, "per_page", "2", args.Get("per_page"))
+      return `<rsp stat="ok">
+        <topics group_id="46744914@N00" iconserver="1" iconfarm="1" name="Tell your story"
+            members="12428" privacy="3" lang="en-us" ispoolmoderated="1"
+            total="4621" page="1" per_page="2" pages="2310">
+          <topic id="72157625038324579" subject="A long time ago" author="53930889@N04"
+              authorname="HAMMER" role="member" iconserver="4068" iconfarm="5"
+              count_replies="8" can_edit="0" can_delete="0" can_reply="1"
+              is_sticky="0" is_locked="1" is_pinned="1"
+              datecreate="1287070965" datelastpost="1336905518">
+            <message>It was the summer of &lt;b&gt;69&lt;/b&gt;</message>
+          </topic>
+          <topic id="72157629635119774" subject="Hello" author="12@N01" authorname="bob"
+              role="admin" count_replies="0" datecreate="1336900000" datelastpost="1336900000"/>
+        </topics>
+      </rsp>`
+    case "flickr.groups.discuss.topics.getInfo":
+      assertEq(t, "topic_id", "72157625038324579", args.Get("topic_id"))
+      return `<rsp stat="ok">
+        <topic id="72157625038324579" subject="A long time ago" group_id="46744914@N00"
+            author="53930889@N04" authorname="HAMMER" role="member" count_replies="8"
+            can_edit="1" datecreate="1287070965" datelastpost="1336905518">
+          <message>It was the summer of 69</message>
+        </topic>
+      </rsp>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return ""
+  })
+  r, err := c.GetTopics("46744914@N00", 0, 2)
+  assertOK(t, "GetTopics", err)
+  assertEq(t, "total", 4621, r.Total)
+  assertEq(t, "perpage", 2, r.PerPage)
+  assertEq(t, "name", "Tell your story", r.Name)
+  assertEq(t, "len", 2, len(r.Topics))
+  tp := r.Topics[0]
+  assertEq(t, "id", "72157625038324579", tp.ID)
+  assertEq(t, "subject", "A long time ago", tp.Subject)
+  assertEq(t, "authorname", "HAMMER", tp.AuthorName)
+  assertEq(t, "role", "member", tp.Role)
+  assertEq(t, "replies", 8, tp.Replies)
+  assert(t, "pinned", tp.IsPinned)
+  assert(t, "locked", tp.IsLocked)
+  assert(t, "can reply", tp.CanReply)
+  assertEq(t, "datelastpost", int64(1336905518), tp.LastPostTime().Unix())
+  assertEq(t, "message", "It was the summer of <b>69</b>", tp.Message)
+  assertEq(t, "role", "admin", r.Topics[1].Role)
+
+  tp2, err := c.GetTopicInfo("72157625038324579")
+  assertOK(t, "GetTopicInfo", err)
+  assertEq(t, "group_id", "46744914@N00", tp2.GroupID)
+  assert(t, "can edit", tp2.CanEdit)
+  assertEq(t, "datecreate", int64(1287070965), tp2.CreateTime().Unix())
+
+  c = newExpectClient(t, "flickr.groups.discuss.topics.add", map[string]string{
+    "group_id": "46744914@N00", "subject": "Rules", "message": "Be nice"})
+  assertOK(t, "AddTopic", c.AddTopic("46744914@N00", "Rules", "Be nice"))
+}
+
+func TestGetReplies(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.groups.discuss.replies.getList":
+      assertEq(t, "topic_id", "72157625038324579", args.Get("topic_id"))
+      assertEq(t, "page", "3", args.Get("page"))
+      return `<rsp stat="ok">
+        <replies>
+          <topic topic_id="72157625038324579" subject="A long time ago" group_id="46744914@N00"
+              author="53930889@N04" authorname="HAMMER" role="member" can_reply="1"
+              datecreate="1287070965" datelastpost="1336905518"
+              total="8" page="3" per_page="3" pages="3">
+            <message>It was the summer of 69</message>
+          </topic>
+          <reply id="72157629635119774" author="41380738@N05" authorname="Jeff"
+              role="moderator" can_edit="0" can_delete="1"
+              datecreate="1336905518" lastedit="1336905600">
+            <message>Great story</message>
+          </reply>
+          <reply id="72157629635119775" author="12@N01" authorname="bob" role="member"
+              datecreate="1336905700" lastedit="0">
+            <message>+1</message>
+          </reply>
+        </replies>
+      </rsp>`
+    case "flickr.groups.discuss.replies.getInfo":
+      assertEq(t, "reply_id", "72157629635119774", args.Get("reply_id"))
+      return `<rsp stat="ok">
+        <reply id="72157629635119774" author="41380738@N05" authorname="Jeff"
+            role="moderator" datecreate="1336905518" lastedit="">
+          <message>Great story</message>
+        </reply>
+      </rsp>`
+    }
+    t.Errorf("unexpected method %s", method)
+    return ""
+  })
+  r, err := c.GetReplies("72157625038324579", 3, 0)
+  assertOK(t, "GetReplies", err)
+  assertEq(t, "paging", Paging{Page: 3, Pages: 3, PerPage: 3, Total: 8}, r.Paging)
+  assertEq(t, "topic id", "72157625038324579", r.Topic.ID)
+  assertEq(t, "subject", "A long time ago", r.Topic.Subject)
+  assertEq(t, "group_id", "46744914@N00", r.Topic.GroupID)
+  assertEq(t, "len", 2, len(r.Replies))
+  rp := r.Replies[0]
+  assertEq(t, "id", "72157629635119774", rp.ID)
+  assertEq(t, "role", "moderator", rp.Role)
+  assert(t, "can delete", rp.CanDelete)
+  assertEq(t, "lastedit", int64(1336905600), rp.LastEditTime().Unix())
+  assertEq(t, "message", "Great story", rp.Message)
+  assert(t, "lastedit 0", r.Replies[1].LastEditTime().IsZero())
+
+  rp2, err := c.GetReplyInfo("72157625038324579", "72157629635119774")
+  assertOK(t, "GetReplyInfo", err)
+  assertEq(t, "authorname", "Jeff", rp2.AuthorName)
+  assert(t, "never edited", rp2.LastEditTime().IsZero())
+}
+
+func TestReplyWrites(t *testing.T) {
+  c := newExpectClient(t, "flickr.groups.discuss.replies.add",
+    map[string]string{"topic_id": "1", "message": "hi"})
+  assertOK(t, "AddReply", c.AddReply("1", "hi"))
+  c = newExpectClient(t, "flickr.groups.discuss.replies.edit",
+    map[string]string{"topic_id": "1", "reply_id": "2", "message": "hello"})
+  assertOK(t, "EditReply", c.EditReply("1", "2", "hello"))
+  c = newExpectClient(t, "flickr.groups.discuss.replies.delete",
+    map[string]string{"topic_id": "1", "reply_id": "2"})
+  assertOK(t, "DeleteReply", c.DeleteReply("1", "2"))
+}
+
+//-----------------------
+// Tests for galleries.go
+//
+func TestGalleryWrites(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.galleries.create", method)
+    assertEq(t, "title", "Cats", args.Get("title"))
+    _, ok := args["primary_photo_id"]
+    assert(t, "primary_photo_id", !ok)
+    return `<rsp stat="ok"><gallery id="50736-72157623680420409"
+        url="http://www.flickr.com/photos/kellan/galleries/72157623680420409"/></rsp>`
+  })
+  id, err := c.CreateGallery("Cats", "Only cats", "")
+  assertOK(t, "CreateGallery", err)
+  assertEq(t, "id", "50736-72157623680420409", id)
+
+  c = newExpectClient(t, "flickr.galleries.addPhoto", map[string]string{
+    "gallery_id": "g1", "photo_id": "42", "comment": "so fluffy"})
+  assertOK(t, "AddPhotoToGallery", c.AddPhotoToGallery("g1", "42", "so fluffy"))
+  c = newExpectClient(t, "flickr.galleries.removePhoto",
+    map[string]string{"gallery_id": "g1", "photo_id": "42"})
+  assertOK(t, "RemovePhotoFromGallery", c.RemovePhotoFromGallery("g1", "42"))
+  c = newExpectClient(t, "flickr.galleries.editMeta",
+    map[string]string{"gallery_id": "g1", "title": "Dogs", "description": ""})
+  assertOK(t, "EditGalleryMeta", c.EditGalleryMeta("g1", "Dogs", ""))
+  c = newExpectClient(t, "flickr.galleries.editPhotos", map[string]string{
+    "gallery_id": "g1", "primary_photo_id": "2", "photo_ids": "1,2,3"})
+  assertOK(t, "EditGalleryPhotos", c.EditGalleryPhotos("g1", "2", []string{"1", "2", "3"}))
+}
+
+const testGallery = `<gallery id="6065-72157617483228192"
+    url="http://www.flickr.com/photos/straup/galleries/72157617483228192"
+    owner="35034348999@N01" primary_photo_id="3492197880"
+    date_create="1241028772" date_update="1270111667"
+    count_photos="18" count_videos="1" count_views="120" count_comments="2"
+    primary_photo_server="3612" primary_photo_farm="4" primary_photo_secret="f3c0f5e8c6">
+  <title>Cat Pictures I've Sent To Kevin Collins</title>
+  <description>Meow</description>
+</gallery>`
+
+func TestGetGalleryInfo(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.galleries.getInfo", method)
+    assertEq(t, "gallery_id", "6065-72157617483228192", args.Get("gallery_id"))
+    return `<rsp stat="ok">` + testGallery + `</rsp>`
+  })
+  g, err := c.GetGalleryInfo("6065-72157617483228192")
+  assertOK(t, "GetGalleryInfo", err)
+  assertEq(t, "page url", "http://www.flickr.com/photos/straup/galleries/72157617483228192", g.PageURL)
+  assertEq(t, "owner", "35034348999@N01", g.Owner)
+  assertEq(t, "title", "Cat Pictures I've Sent To Kevin Collins", g.Title)
+  assertEq(t, "description", "Meow", g.Description)
+  assertEq(t, "photos", 18, g.Photos)
+  assertEq(t, "videos", 1, g.Videos)
+  assertEq(t, "views", 120, g.CountViews)
+  assertEq(t, "comments", 2, g.CountComments)
+  assertEq(t, "created", int64(1241028772), g.CreateTime().Unix())
+  assertEq(t, "primary", "http://farm4.static.flickr.com/3612/3492197880_f3c0f5e8c6_s.jpg",
+    g.URL(SizeSmallSquare))
+}
+
+func TestGetGalleries(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    switch method {
+    case "flickr.galleries.getList":
+      assertEq(t, "user_id", "35034348999@N01", args.Get("user_id"))
+      assertEq(t, "primary_photo_extras", "url_s", args.Get("primary_photo_extras"))
+    case "flickr.galleries.getListForPhoto":
+      assertEq(t, "photo_id", "3492197880", args.Get("photo_id"))
+    default:
+      t.Errorf("unexpected method %s", method)
+    }
+    return `<rsp stat="ok"><galleries total="9" page="2" pages="5" per_page="2"
+        user_id="35034348999@N01">` + testGallery + `</galleries></rsp>`
+  })
+  r, err := c.GetGalleries("35034348999@N01", map[string]string{"primary_photo_extras": "url_s"})
+  assertOK(t, "GetGalleries", err)
+  assertEq(t, "paging", Paging{Page: 2, Pages: 5, PerPage: 2, Total: 9}, r.Paging)
+  assertEq(t, "len", 1, len(r.Galleries))
+  assertEq(t, "id", "6065-72157617483228192", r.Galleries[0].ID)
+  assertEq(t, "primary id", "3492197880", r.Galleries[0].Photo.ID)
+
+  r, err = c.GetGalleriesForPhoto("3492197880", nil)
+  assertOK(t, "GetGalleriesForPhoto", err)
+  assertEq(t, "total", 9, r.Total)
+}
+
+func TestGetGalleryPhotos(t *testing.T) {
+  c := newRoutingClient(t, func(method string, args url.Values) string {
+    assertEq(t, "method", "flickr.galleries.getPhotos", method)
+    assertEq(t, "gallery_id", "g1", args.Get("gallery_id"))
+    return `<rsp stat="ok"><photos page="1" pages="1" perpage="500" total="2">
+      <photo id="2822546461" owner="78188707@N00" secret="2dbcdb589f" server="1"
+          farm="1" title="FOO" ispublic="1" isfriend="0" isfamily="0" is_primary="1"
+          has_comment="1">
+        <comment>best cat ever</comment>
+      </photo>
+      <photo id="2822544806" owner="78188707@N00" secret="bd93ba8d44" server="1"
+          farm="1" title="BAR" ispublic="1" isfriend="0" isfamily="0" has_comment="0"/>
+    </photos></rsp>`
+  })
+  r, err := c.GetGalleryPhotos("g1", nil)
+  assertOK(t, "GetGalleryPhotos", err)
+  assertEq(t, "len", 2, len(r.Photos))
+  assertEq(t, "title", "FOO", r.Photos[0].Title)
+  assertEq(t, "comment", "best cat ever", r.Photos[0].GalleryComment)
+  assertEq(t, "no comment", "", r.Photos[1].GalleryComment)
+
+  n := 0
+  it := c.GetGalleryPhotosAll("g1", nil)
+  for it.Next() {
+    n++
+  }
+  assertOK(t, "GetGalleryPhotosAll", it.Err())
+  assertEq(t, "all", 2, n)
 }
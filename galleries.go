@@ -0,0 +1,212 @@
+package flickgo
+
+import (
+  "strings"
+  "time"
+)
+
+// A gallery: a curated list of other users' photos.
+type Gallery struct {
+  // The primary (cover) photo; URL gives the cover image.  Its ID is in
+  // Photo.ID, as ID is the gallery's.
+  Photo
+
+  ID          string `xml:"id,attr"`
+  PageURL     string `xml:"url,attr"`
+  Owner       string `xml:"owner,attr"`
+  Title       string `xml:"title"`
+  Description string `xml:"description"`
+
+  Photos        int `xml:"count_photos,attr"`
+  Videos        int `xml:"count_videos,attr"`
+  CountViews    int `xml:"count_views,attr"`
+  CountComments int `xml:"count_comments,attr"`
+
+  DateCreate string `xml:"date_create,attr"` // Unix timestamp
+  DateUpdate string `xml:"date_update,attr"` // Unix timestamp
+
+  // Extras of the primary photo, if requested with primary_photo_extras.
+  PrimaryPhotoExtras *SearchPhoto `xml:"primary_photo_extras"`
+}
+
+func (g *Gallery) CreateTime() time.Time {
+  return optionalTime(g.DateCreate)
+}
+
+func (g *Gallery) UpdateTime() time.Time {
+  return optionalTime(g.DateUpdate)
+}
+
+// A gallery as returned by Flickr, where the primary photo's attributes have
+// a prefix.
+type galleryElem struct {
+  Gallery
+
+  PrimaryPhotoID     string `xml:"primary_photo_id,attr"`
+  PrimaryPhotoSecret string `xml:"primary_photo_secret,attr"`
+  PrimaryPhotoServer string `xml:"primary_photo_server,attr"`
+  PrimaryPhotoFarm   string `xml:"primary_photo_farm,attr"`
+}
+
+// Returns the Gallery of g.
+func (g *galleryElem) gallery() *Gallery {
+  r := g.Gallery
+  r.Photo = Photo{
+    ID:     g.PrimaryPhotoID,
+    Secret: g.PrimaryPhotoSecret,
+    Server: g.PrimaryPhotoServer,
+    Farm:   g.PrimaryPhotoFarm,
+  }
+  return &r
+}
+
+// A page of galleries.
+type GalleriesResponse struct {
+  Paging
+
+  Galleries []Gallery `xml:"gallery"`
+}
+
+// Creates a new gallery and returns its ID.  primaryPhotoID may be empty.
+func (c *Client) CreateGallery(title, description, primaryPhotoID string) (string, error) {
+  args := map[string]string{
+    "title":       title,
+    "description": description,
+  }
+  if primaryPhotoID != "" {
+    args["primary_photo_id"] = primaryPhotoID
+  }
+  req, err := postRequest(c, "flickr.galleries.create", args)
+  if err != nil {
+    return "", err
+  }
+  r := struct {
+    Stat    string      `xml:"stat,attr"`
+    Err     flickrError `xml:"err"`
+    Gallery struct {
+      ID string `xml:"id,attr"`
+    } `xml:"gallery"`
+  }{}
+  if err := flickrPost(c, req, &r); err != nil {
+    return "", err
+  }
+  if r.Stat != "ok" {
+    return "", r.Err.Err()
+  }
+  return r.Gallery.ID, nil
+}
+
+// Adds a photo to a gallery, with an optional comment.
+func (c *Client) AddPhotoToGallery(galleryID, photoID, comment string) error {
+  args := map[string]string{
+    "gallery_id": galleryID,
+    "photo_id":   photoID,
+  }
+  if comment != "" {
+    args["comment"] = comment
+  }
+  return postOK(c, "flickr.galleries.addPhoto", args)
+}
+
+// Removes a photo from a gallery.
+func (c *Client) RemovePhotoFromGallery(galleryID, photoID string) error {
+  args := map[string]string{
+    "gallery_id": galleryID,
+    "photo_id":   photoID,
+  }
+  return postOK(c, "flickr.galleries.removePhoto", args)
+}
+
+// Changes the title and description of a gallery.
+func (c *Client) EditGalleryMeta(galleryID, title, description string) error {
+  args := map[string]string{
+    "gallery_id":  galleryID,
+    "title":       title,
+    "description": description,
+  }
+  return postOK(c, "flickr.galleries.editMeta", args)
+}
+
+// Replaces the photos of a gallery with photoIDs, in that order.
+// primaryPhotoID must be one of photoIDs.
+func (c *Client) EditGalleryPhotos(galleryID, primaryPhotoID string, photoIDs []string) error {
+  args := map[string]string{
+    "gallery_id":       galleryID,
+    "primary_photo_id": primaryPhotoID,
+    "photo_ids":        strings.Join(photoIDs, ","),
+  }
+  return postOK(c, "flickr.galleries.editPhotos", args)
+}
+
+// Returns URL for flickr.galleries.getInfo request.
+func getGalleryInfoURL(c *Client, galleryID string) string {
+  args := make(map[string]string)
+  args["gallery_id"] = galleryID
+  return makeURL(c, "flickr.galleries.getInfo", args, true)
+}
+
+// Returns a gallery.
+func (c *Client) GetGalleryInfo(galleryID string) (*Gallery, error) {
+  r := struct {
+    Stat    string      `xml:"stat,attr"`
+    Err     flickrError `xml:"err"`
+    Gallery galleryElem `xml:"gallery"`
+  }{}
+  if err := flickrGet(c, getGalleryInfoURL(c, galleryID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Gallery.gallery(), nil
+}
+
+// Sends a galleries request that returns a page of galleries.
+func getGalleryList(c *Client, method string, args map[string]string) (*GalleriesResponse, error) {
+  r := struct {
+    Stat     string      `xml:"stat,attr"`
+    Err      flickrError `xml:"err"`
+    Response struct {
+      Paging
+
+      // Galleries spell perpage differently than other lists.
+      PerPage   int           `xml:"per_page,attr"`
+      Galleries []galleryElem `xml:"gallery"`
+    } `xml:"galleries"`
+  }{}
+  if err := flickrGet(c, makeURL(c, method, args, true), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  resp := &GalleriesResponse{Paging: r.Response.Paging}
+  resp.PerPage = r.Response.PerPage
+  for _, g := range r.Response.Galleries {
+    resp.Galleries = append(resp.Galleries, *g.gallery())
+  }
+  return resp, nil
+}
+
+// Returns a page of the galleries of a user.  args may contain
+// primary_photo_extras, page and per_page.
+func (c *Client) GetGalleries(userID string, args map[string]string) (*GalleriesResponse, error) {
+  return getGalleryList(c, "flickr.galleries.getList", withArg(args, "user_id", userID))
+}
+
+// Returns a page of the galleries a photo is in.  args may contain page and
+// per_page.
+func (c *Client) GetGalleriesForPhoto(photoID string, args map[string]string) (*GalleriesResponse, error) {
+  return getGalleryList(c, "flickr.galleries.getListForPhoto", withArg(args, "photo_id", photoID))
+}
+
+// Returns a page of the photos in a gallery.  args may contain extras, page
+// and per_page.  The photos' GalleryComment is set.
+func (c *Client) GetGalleryPhotos(galleryID string, args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.galleries.getPhotos", withArg(args, "gallery_id", galleryID))
+}
+
+// Returns an iterator over all photos in a gallery.
+func (c *Client) GetGalleryPhotosAll(galleryID string, args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.galleries.getPhotos", withArg(args, "gallery_id", galleryID))
+}
@@ -0,0 +1,200 @@
+package flickgo
+
+import (
+  "strconv"
+)
+
+// Where a photo was taken, relative to buildings.
+type GeoContext int
+
+const (
+  GeoContextNone GeoContext = iota
+  GeoContextIndoors
+  GeoContextOutdoors
+)
+
+// Accuracy of a location, from 1 (world) to 16 (street).
+const (
+  AccuracyWorld   = 1
+  AccuracyCountry = 3
+  AccuracyRegion  = 6
+  AccuracyCity    = 11
+  AccuracyStreet  = 16
+)
+
+// A place in the hierarchy of a location or place, like its locality or
+// country.
+type LocationPlace struct {
+  PlaceID string `xml:"place_id,attr"`
+  WOEID   string `xml:"woeid,attr"`
+  Name    string `xml:",chardata"`
+
+  // Only set in the hierarchy of a Place.
+  Latitude  float64 `xml:"latitude,attr"`
+  Longitude float64 `xml:"longitude,attr"`
+  PlaceURL  string  `xml:"place_url,attr"`
+}
+
+// The geographic location of a photo.
+type Location struct {
+  Latitude  float64    `xml:"latitude,attr"`
+  Longitude float64    `xml:"longitude,attr"`
+  Accuracy  int        `xml:"accuracy,attr"`
+  Context   GeoContext `xml:"context,attr"`
+
+  // Most precise place containing the location.
+  PlaceID string `xml:"place_id,attr"`
+  WOEID   string `xml:"woeid,attr"`
+
+  Neighbourhood LocationPlace `xml:"neighbourhood"`
+  Locality      LocationPlace `xml:"locality"`
+  County        LocationPlace `xml:"county"`
+  Region        LocationPlace `xml:"region"`
+  Country       LocationPlace `xml:"country"`
+}
+
+// Who may see the location of a photo.
+type GeoPerms struct {
+  IsPublic  bool `xml:"ispublic,attr"`
+  IsContact bool `xml:"iscontact,attr"`
+  IsFriend  bool `xml:"isfriend,attr"`
+  IsFamily  bool `xml:"isfamily,attr"`
+}
+
+// Formats a latitude or longitude for a request.
+func formatDegrees(d float64) string {
+  return strconv.FormatFloat(d, 'f', -1, 64)
+}
+
+// Returns URL for flickr.photos.geo.getLocation request.
+func getLocationURL(c *Client, photoID string) string {
+  return singlePhotoURL(c, photoID, "flickr.photos.geo.getLocation")
+}
+
+// Returns the location of a photo.  Flickr returns an error if the photo has
+// none.
+func (c *Client) GetLocation(photoID string) (*Location, error) {
+  r := struct {
+    Stat     string      `xml:"stat,attr"`
+    Err      flickrError `xml:"err"`
+    Location Location    `xml:"photo>location"`
+  }{}
+  if err := flickrGet(c, getLocationURL(c, photoID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Location, nil
+}
+
+// Sets the location of a photo.  accuracy and context are left to Flickr's
+// defaults when zero.
+func (c *Client) SetLocation(photoID string, lat, lon float64, accuracy int,
+  context GeoContext) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "lat":      formatDegrees(lat),
+    "lon":      formatDegrees(lon),
+  }
+  if accuracy != 0 {
+    args["accuracy"] = strconv.Itoa(accuracy)
+  }
+  if context != GeoContextNone {
+    args["context"] = strconv.Itoa(int(context))
+  }
+  return postOK(c, "flickr.photos.geo.setLocation", args)
+}
+
+// Removes the location of a photo.
+func (c *Client) RemoveLocation(photoID string) error {
+  return postOK(c, "flickr.photos.geo.removeLocation", map[string]string{"photo_id": photoID})
+}
+
+// Sets the geo context of a photo.
+func (c *Client) SetGeoContext(photoID string, context GeoContext) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "context":  strconv.Itoa(int(context)),
+  }
+  return postOK(c, "flickr.photos.geo.setContext", args)
+}
+
+// Returns URL for flickr.photos.geo.getPerms request.
+func getGeoPermsURL(c *Client, photoID string) string {
+  return singlePhotoURL(c, photoID, "flickr.photos.geo.getPerms")
+}
+
+// Returns who may see the location of a photo.
+func (c *Client) GetGeoPerms(photoID string) (*GeoPerms, error) {
+  r := struct {
+    Stat  string      `xml:"stat,attr"`
+    Err   flickrError `xml:"err"`
+    Perms GeoPerms    `xml:"perms"`
+  }{}
+  if err := flickrGet(c, getGeoPermsURL(c, photoID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Perms, nil
+}
+
+// Sets who may see the location of a photo.
+func (c *Client) SetGeoPerms(photoID string, perms GeoPerms) error {
+  args := map[string]string{
+    "photo_id":   photoID,
+    "is_public":  boolArg(perms.IsPublic),
+    "is_contact": boolArg(perms.IsContact),
+    "is_friend":  boolArg(perms.IsFriend),
+    "is_family":  boolArg(perms.IsFamily),
+  }
+  return postOK(c, "flickr.photos.geo.setPerms", args)
+}
+
+// Corrects the place of all the calling user's photos at a location.  Exactly
+// one of placeID and woeID must be set.
+func (c *Client) BatchCorrectLocation(lat, lon float64, accuracy int,
+  placeID, woeID string) error {
+  args := map[string]string{
+    "lat":      formatDegrees(lat),
+    "lon":      formatDegrees(lon),
+    "accuracy": strconv.Itoa(accuracy),
+  }
+  if placeID != "" {
+    args["place_id"] = placeID
+  }
+  if woeID != "" {
+    args["woe_id"] = woeID
+  }
+  return postOK(c, "flickr.photos.geo.batchCorrectLocation", args)
+}
+
+// Returns a copy of args with the location set.
+func locationArgs(args map[string]string, lat, lon float64, accuracy int) map[string]string {
+  a := clone(args)
+  a["lat"] = formatDegrees(lat)
+  a["lon"] = formatDegrees(lon)
+  if accuracy != 0 {
+    a["accuracy"] = strconv.Itoa(accuracy)
+  }
+  return a
+}
+
+// Returns a page of the calling user's photos taken at a location.  accuracy
+// is left to Flickr's default when zero.  args may contain extras, page and
+// per_page.
+func (c *Client) PhotosForLocation(lat, lon float64, accuracy int,
+  args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.photos.geo.photosForLocation",
+    locationArgs(args, lat, lon, accuracy))
+}
+
+// Returns an iterator over all of the calling user's photos taken at a
+// location.
+func (c *Client) PhotosForLocationAll(lat, lon float64, accuracy int,
+  args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.photos.geo.photosForLocation",
+    locationArgs(args, lat, lon, accuracy))
+}
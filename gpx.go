@@ -0,0 +1,276 @@
+package flickgo
+
+import (
+  "bytes"
+  "encoding/xml"
+  "errors"
+  "fmt"
+  "io"
+  "sort"
+  "time"
+)
+
+// Default for Geotagger.MaxGap.
+const DefaultGeotagMaxGap = 5 * time.Minute
+
+// A point of a GPS track.
+type TrackPoint struct {
+  Lat  float64
+  Lon  float64
+  Ele  float64
+  Time time.Time
+}
+
+// A GPS track: timed points sorted by time.
+type Track []TrackPoint
+
+// Parses the track points of a GPX file.  The points of all tracks and
+// segments are merged; points without a time are dropped.
+func ParseGPX(r io.Reader) (Track, error) {
+  doc := struct {
+    Points []struct {
+      Lat  float64 `xml:"lat,attr"`
+      Lon  float64 `xml:"lon,attr"`
+      Ele  float64 `xml:"ele"`
+      Time string  `xml:"time"`
+    } `xml:"trk>trkseg>trkpt"`
+  }{}
+  if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+    return nil, wrapErr("parsing GPX failed", err)
+  }
+  var t Track
+  for _, p := range doc.Points {
+    if p.Time == "" {
+      continue
+    }
+    at, err := time.Parse(time.RFC3339, p.Time)
+    if err != nil {
+      return nil, wrapErr("invalid GPX time", err)
+    }
+    t = append(t, TrackPoint{Lat: p.Lat, Lon: p.Lon, Ele: p.Ele, Time: at})
+  }
+  return t.Merge(nil), nil
+}
+
+// Returns a new track with the points of t and other, sorted by time.
+func (t Track) Merge(other Track) Track {
+  m := make(Track, 0, len(t)+len(other))
+  m = append(m, t...)
+  m = append(m, other...)
+  sort.Stable(byTime(m))
+  return m
+}
+
+// Sorts track points by time.
+type byTime Track
+
+func (t byTime) Len() int           { return len(t) }
+func (t byTime) Less(i, j int) bool { return t[i].Time.Before(t[j].Time) }
+func (t byTime) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+
+// Returns the position at time at.  Between two points at most maxGap
+// apart, the position is interpolated linearly; otherwise the nearest point
+// is used, if it is within maxGap of at.  ok is false if there is no such
+// point.
+func (t Track) Locate(at time.Time, maxGap time.Duration) (p TrackPoint, ok bool) {
+  i := sort.Search(len(t), func(i int) bool { return !t[i].Time.Before(at) })
+  if i < len(t) && t[i].Time.Equal(at) {
+    return t[i], true
+  }
+  if i > 0 && i < len(t) {
+    prev, next := t[i-1], t[i]
+    if span := next.Time.Sub(prev.Time); span <= maxGap {
+      f := float64(at.Sub(prev.Time)) / float64(span)
+      return TrackPoint{
+        Lat:  prev.Lat + f*(next.Lat-prev.Lat),
+        Lon:  prev.Lon + f*(next.Lon-prev.Lon),
+        Ele:  prev.Ele + f*(next.Ele-prev.Ele),
+        Time: at,
+      }, true
+    }
+  }
+
+  // Outside the track, or in a gap: use the nearest point.
+  var nearest *TrackPoint
+  var dist time.Duration
+  for _, j := range []int{i - 1, i} {
+    if j < 0 || j >= len(t) {
+      continue
+    }
+    d := t[j].Time.Sub(at)
+    if d < 0 {
+      d = -d
+    }
+    if nearest == nil || d < dist {
+      nearest, dist = &t[j], d
+    }
+  }
+  if nearest == nil || dist > maxGap {
+    return TrackPoint{}, false
+  }
+  return TrackPoint{Lat: nearest.Lat, Lon: nearest.Lon, Ele: nearest.Ele, Time: at}, true
+}
+
+// Outcome of geotagging a single photo.
+type GeotagResult struct {
+  PhotoID string
+
+  // When the photo was taken, corrected for the camera clock.
+  Taken time.Time
+
+  // Position found on the track; valid if Matched is set.
+  Point   TrackPoint
+  Matched bool
+
+  // Set if the photo already had a location and was left alone.
+  HasLocation bool
+
+  // Whether the location was set; false for dry runs.
+  Applied bool
+
+  Err error
+}
+
+func (r *GeotagResult) String() string {
+  switch {
+  case r.Err != nil:
+    return fmt.Sprintf("%s: %s", r.PhotoID, r.Err)
+  case r.HasLocation:
+    return fmt.Sprintf("%s: already has a location", r.PhotoID)
+  case !r.Matched:
+    return fmt.Sprintf("%s: taken %s, no track point", r.PhotoID,
+      r.Taken.Format(time.RFC3339))
+  }
+  return fmt.Sprintf("%s: taken %s, at %.6f,%.6f", r.PhotoID,
+    r.Taken.Format(time.RFC3339), r.Point.Lat, r.Point.Lon)
+}
+
+// Summary of a Geotagger run.
+type GeotagReport struct {
+  // One result per photo, in the order the photos were given.
+  Results []GeotagResult
+
+  // Number of photos matched to the track, whether or not they were updated.
+  Matched   int
+  Unmatched int
+  Skipped   int
+  Failed    int
+}
+
+// Returns the report in a human readable form, one photo per line followed
+// by the totals.
+func (r *GeotagReport) String() string {
+  var b bytes.Buffer
+  for i := range r.Results {
+    fmt.Fprintln(&b, r.Results[i].String())
+  }
+  fmt.Fprintf(&b, "%d matched, %d unmatched, %d skipped, %d failed\n",
+    r.Matched, r.Unmatched, r.Skipped, r.Failed)
+  return b.String()
+}
+
+// Sets the location of photos from a GPS track, by matching the time each
+// photo was taken to the track.
+type Geotagger struct {
+  Client *Client
+  Track  Track
+
+  // How far ahead of the true time the camera clock was; subtracted from the
+  // taken times.
+  ClockOffset time.Duration
+
+  // Time zone the camera clock was set to.  Flickr stores taken times
+  // without a zone.
+  TimeZone *time.Location
+
+  // Photos taken between two track points at most this far apart get an
+  // interpolated position.  Other photos get the position of the nearest
+  // track point, and are not matched if it is further than this.
+  MaxGap time.Duration
+
+  // Accuracy passed to SetLocation.
+  Accuracy int
+
+  // Set the location of photos that already have one.
+  Overwrite bool
+
+  // Report the matches without setting any location.
+  DryRun bool
+}
+
+// Creates a new Geotagger for c and track with default settings: camera
+// clock in UTC, DefaultGeotagMaxGap and street accuracy.
+func NewGeotagger(c *Client, track Track) *Geotagger {
+  return &Geotagger{
+    Client:   c,
+    Track:    track,
+    TimeZone: time.UTC,
+    MaxGap:   DefaultGeotagMaxGap,
+    Accuracy: AccuracyStreet,
+  }
+}
+
+// Returns the true time at which a photo with dates d was taken.
+func (g *Geotagger) TakenTime(d Dates) (time.Time, error) {
+  loc := g.TimeZone
+  if loc == nil {
+    loc = time.UTC
+  }
+  t, err := time.ParseInLocation(takenLayout, d.Taken, loc)
+  if err != nil {
+    return time.Time{}, wrapErr("invalid taken date", err)
+  }
+  return t.Add(-g.ClockOffset), nil
+}
+
+// Geotags photos one by one, reading their taken time with GetInfo.
+// Failure to geotag one photo doesn't stop the others.
+func (g *Geotagger) Run(photoIDs []string) (*GeotagReport, error) {
+  if len(g.Track) == 0 {
+    return nil, errors.New("empty GPS track")
+  }
+  report := &GeotagReport{Results: make([]GeotagResult, len(photoIDs))}
+  for i, id := range photoIDs {
+    res := &report.Results[i]
+    res.PhotoID = id
+    g.process(res)
+    switch {
+    case res.Err != nil:
+      report.Failed++
+    case res.HasLocation:
+      report.Skipped++
+    case res.Matched:
+      report.Matched++
+    default:
+      report.Unmatched++
+    }
+  }
+  return report, nil
+}
+
+// Geotags a single photo.
+func (g *Geotagger) process(res *GeotagResult) {
+  info, err := g.Client.GetInfo(res.PhotoID)
+  if err != nil {
+    res.Err = wrapErr("getting photo info failed", err)
+    return
+  }
+  if info.Location != nil && !g.Overwrite {
+    res.HasLocation = true
+    return
+  }
+  if res.Taken, res.Err = g.TakenTime(info.Dates); res.Err != nil {
+    return
+  }
+  res.Point, res.Matched = g.Track.Locate(res.Taken, g.MaxGap)
+  if !res.Matched || g.DryRun {
+    return
+  }
+  err = g.Client.SetLocation(res.PhotoID, res.Point.Lat, res.Point.Lon,
+    g.Accuracy, GeoContextNone)
+  if err != nil {
+    res.Err = wrapErr("setting location failed", err)
+    return
+  }
+  res.Applied = true
+}
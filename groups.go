@@ -0,0 +1,325 @@
+package flickgo
+
+import (
+  "errors"
+)
+
+// Returned by SubmitToPool when the calling user may not add more photos to
+// the group's pool for now.
+var ErrPoolThrottled = errors.New("group pool throttle reached")
+
+// Who may see and join a group.
+type GroupPrivacy int
+
+const (
+  GroupPrivate    GroupPrivacy = 1
+  GroupInviteOnly GroupPrivacy = 2
+  GroupPublic     GroupPrivacy = 3
+)
+
+// Limit on how many photos a member may add to a group's pool.
+type Throttle struct {
+  // Photos allowed per period.
+  Count int `xml:"count,attr"`
+
+  // Period: "day", "week", "month" or "ever"; "none" for no limit, or
+  // "disabled" if no photos may be added.
+  Mode string `xml:"mode,attr"`
+
+  // Photos the calling user may still add in the current period.
+  Remaining int `xml:"remaining,attr"`
+}
+
+// Reports whether the calling user may add a photo now.
+func (t *Throttle) CanAdd() bool {
+  switch t.Mode {
+  case "", "none":
+    return true
+  case "disabled":
+    return false
+  }
+  return t.Remaining > 0
+}
+
+// A Flickr group.
+type Group struct {
+  NSID       string       `xml:"nsid,attr"`
+  Name       string       `xml:"name,attr"`
+  Members    int          `xml:"members,attr"`
+  PoolCount  int          `xml:"pool_count,attr"`
+  TopicCount int          `xml:"topic_count,attr"`
+  Privacy    GroupPrivacy `xml:"privacy,attr"`
+  IconServer string       `xml:"iconserver,attr"`
+  IconFarm   string       `xml:"iconfarm,attr"`
+
+  EighteenPlus   bool `xml:"eighteenplus,attr"`
+  InvitationOnly bool `xml:"invitation_only,attr"`
+
+  // Whether the calling user is an admin of the group.
+  IsAdmin bool `xml:"admin,attr"`
+
+  // Only set by GetGroupInfo.
+  PathAlias       string   `xml:"path_alias,attr"`
+  Description     string   `xml:"description"`
+  IsPoolModerated bool     `xml:"ispoolmoderated,attr"`
+  Throttle        Throttle `xml:"throttle"`
+}
+
+// A group as returned by flickr.groups.getInfo, where most fields are
+// elements rather than attributes.
+type groupElem struct {
+  Group
+
+  ID         string       `xml:"id,attr"`
+  Name       string       `xml:"name"`
+  Members    int          `xml:"members"`
+  PoolCount  int          `xml:"pool_count"`
+  TopicCount int          `xml:"topic_count"`
+  Privacy    GroupPrivacy `xml:"privacy"`
+}
+
+// Returns the Group of g.
+func (g *groupElem) group() *Group {
+  r := g.Group
+  r.NSID = g.ID
+  r.Name = g.Name
+  r.Members = g.Members
+  r.PoolCount = g.PoolCount
+  r.TopicCount = g.TopicCount
+  r.Privacy = g.Privacy
+  return &r
+}
+
+// A page of groups.
+type GroupsResponse struct {
+  Paging
+
+  Groups []Group `xml:"group"`
+}
+
+// Returns URL for flickr.groups.search request.
+func searchGroupsURL(c *Client, text string, page, perPage int) string {
+  args := make(map[string]string)
+  args["text"] = text
+  setPage(args, page, perPage)
+  return makeURL(c, "flickr.groups.search", args, true)
+}
+
+// Returns a page of the public groups matching text.  page and perPage are
+// ignored when zero.
+func (c *Client) SearchGroups(text string, page, perPage int) (*GroupsResponse, error) {
+  r := struct {
+    Stat     string         `xml:"stat,attr"`
+    Err      flickrError    `xml:"err"`
+    Response GroupsResponse `xml:"groups"`
+  }{}
+  if err := flickrGet(c, searchGroupsURL(c, text, page, perPage), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Response, nil
+}
+
+// Returns URL for flickr.groups.getInfo request.
+func getGroupInfoURL(c *Client, groupID string) string {
+  args := make(map[string]string)
+  args["group_id"] = groupID
+  return makeURL(c, "flickr.groups.getInfo", args, true)
+}
+
+// Returns details about a group, including the calling user's pool throttle.
+func (c *Client) GetGroupInfo(groupID string) (*Group, error) {
+  r := struct {
+    Stat  string      `xml:"stat,attr"`
+    Err   flickrError `xml:"err"`
+    Group groupElem   `xml:"group"`
+  }{}
+  if err := flickrGet(c, getGroupInfoURL(c, groupID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Group.group(), nil
+}
+
+// Makes the calling user join a public group.  acceptRules must be set for
+// groups with rules.
+func (c *Client) JoinGroup(groupID string, acceptRules bool) error {
+  args := map[string]string{"group_id": groupID}
+  if acceptRules {
+    args["accept_rules"] = "1"
+  }
+  return postOK(c, "flickr.groups.join", args)
+}
+
+// Makes the calling user leave a group, optionally removing their photos
+// from its pool.
+func (c *Client) LeaveGroup(groupID string, deletePhotos bool) error {
+  args := map[string]string{"group_id": groupID}
+  if deletePhotos {
+    args["delete_photos"] = "1"
+  }
+  return postOK(c, "flickr.groups.leave", args)
+}
+
+// Flickr error codes of flickr.groups.pools.add that aren't failures.
+const (
+  poolErrAlreadyIn      = "3"
+  poolErrPending        = "6"
+  poolErrAlreadyPending = "7"
+)
+
+// Adds a photo to a group's pool.  Adding a photo that is already in the pool
+// is not an error.  pending is set if the photo awaits approval by the
+// group's admins.
+func (c *Client) AddToPool(photoID, groupID string) (pending bool, err error) {
+  args := map[string]string{
+    "photo_id": photoID,
+    "group_id": groupID,
+  }
+  req, rErr := postRequest(c, "flickr.groups.pools.add", args)
+  if rErr != nil {
+    return false, rErr
+  }
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+  }{}
+  if err := flickrPost(c, req, &r); err != nil {
+    return false, err
+  }
+  if r.Stat == "ok" {
+    return false, nil
+  }
+  switch r.Err.Code {
+  case poolErrAlreadyIn:
+    return false, nil
+  case poolErrPending, poolErrAlreadyPending:
+    return true, nil
+  }
+  return false, r.Err.Err()
+}
+
+// Adds a photo to a group's pool, unless the group's throttle doesn't allow
+// the calling user to add more photos, in which case ErrPoolThrottled is
+// returned.  See AddToPool.
+func (c *Client) SubmitToPool(photoID, groupID string) (pending bool, err error) {
+  g, gErr := c.GetGroupInfo(groupID)
+  if gErr != nil {
+    return false, wrapErr("getting group info failed", gErr)
+  }
+  if !g.Throttle.CanAdd() {
+    return false, ErrPoolThrottled
+  }
+  return c.AddToPool(photoID, groupID)
+}
+
+// Removes a photo from a group's pool.
+func (c *Client) RemoveFromPool(photoID, groupID string) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "group_id": groupID,
+  }
+  return postOK(c, "flickr.groups.pools.remove", args)
+}
+
+// Returns a page of the photos in a group's pool.  args may contain tags,
+// user_id, extras, page and per_page.
+func (c *Client) GetPoolPhotos(groupID string, args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.groups.pools.getPhotos", withArg(args, "group_id", groupID))
+}
+
+// Returns an iterator over all photos in a group's pool.
+func (c *Client) GetPoolPhotosAll(groupID string, args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.groups.pools.getPhotos", withArg(args, "group_id", groupID))
+}
+
+// Returns URL for flickr.groups.pools.getContext request.
+func getPoolContextURL(c *Client, photoID, groupID string) string {
+  args := make(map[string]string)
+  args["photo_id"] = photoID
+  args["group_id"] = groupID
+  return makeURL(c, "flickr.groups.pools.getContext", args, true)
+}
+
+// Returns the photos before and after photoID in a group's pool.
+func (c *Client) GetPoolContext(photoID, groupID string) (*PhotoContext, error) {
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    PhotoContext
+  }{}
+  if err := flickrGet(c, getPoolContextURL(c, photoID, groupID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.PhotoContext, nil
+}
+
+// Returns URL for flickr.groups.pools.getGroups request.
+func getPoolGroupsURL(c *Client, page, perPage int) string {
+  args := make(map[string]string)
+  setPage(args, page, perPage)
+  return makeURL(c, "flickr.groups.pools.getGroups", args, true)
+}
+
+// Returns a page of the groups whose pools the calling user may add photos
+// to.  page and perPage are ignored when zero.
+func (c *Client) GetPoolGroups(page, perPage int) (*GroupsResponse, error) {
+  r := struct {
+    Stat     string      `xml:"stat,attr"`
+    Err      flickrError `xml:"err"`
+    Response struct {
+      Paging
+
+      Groups []struct {
+        Group
+
+        // Pool size, under another name than elsewhere.
+        Photos int `xml:"photos,attr"`
+      } `xml:"group"`
+    } `xml:"groups"`
+  }{}
+  if err := flickrGet(c, getPoolGroupsURL(c, page, perPage), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  resp := &GroupsResponse{Paging: r.Response.Paging}
+  for _, g := range r.Response.Groups {
+    if g.PoolCount == 0 {
+      g.PoolCount = g.Photos
+    }
+    resp.Groups = append(resp.Groups, g.Group)
+  }
+  return resp, nil
+}
+
+// Returns URL for flickr.people.getGroups request.
+func getUserGroupsURL(c *Client, userID string) string {
+  args := make(map[string]string)
+  args["user_id"] = userID
+  return makeURL(c, "flickr.people.getGroups", args, true)
+}
+
+// Returns the groups a user is a member of.
+func (c *Client) GetUserGroups(userID string) ([]Group, error) {
+  r := struct {
+    Stat   string      `xml:"stat,attr"`
+    Err    flickrError `xml:"err"`
+    Groups []Group     `xml:"groups>group"`
+  }{}
+  if err := flickrGet(c, getUserGroupsURL(c, userID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Groups, nil
+}
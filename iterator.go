@@ -0,0 +1,77 @@
+package flickgo
+
+import (
+  "strconv"
+)
+
+// Iterates over the photos of a paged photo list, fetching pages as needed.
+// Typical use:
+//     it := c.SearchAll(args)
+//     for it.Next() {
+//       p := it.Photo()
+//       ...
+//     }
+//     if err := it.Err(); err != nil {
+//       ...
+//     }
+type PhotoIterator struct {
+  // Fetches the specified page, starting from 1.
+  fetch func(page int) (*SearchResponse, error)
+
+  page   int
+  pages  int
+  photos []SearchPhoto
+  cur    SearchPhoto
+  err    error
+}
+
+// Returns an iterator over the photos returned by fetch.
+func newPhotoIterator(fetch func(page int) (*SearchResponse, error)) *PhotoIterator {
+  return &PhotoIterator{fetch: fetch, pages: 1}
+}
+
+// Advances to the next photo.  Returns false when there are no more photos
+// or an error occurred.
+func (it *PhotoIterator) Next() bool {
+  for len(it.photos) == 0 {
+    if it.err != nil || it.page >= it.pages {
+      return false
+    }
+    it.page++
+    r, err := it.fetch(it.page)
+    if err != nil {
+      it.err = err
+      return false
+    }
+    if len(r.Photos) == 0 {
+      return false
+    }
+    it.photos = r.Photos
+    if n, err := strconv.Atoi(r.Pages); err == nil {
+      it.pages = n
+    }
+  }
+  it.cur = it.photos[0]
+  it.photos = it.photos[1:]
+  return true
+}
+
+// Returns the current photo.
+func (it *PhotoIterator) Photo() SearchPhoto {
+  return it.cur
+}
+
+// Returns the error that stopped the iteration, if any.
+func (it *PhotoIterator) Err() error {
+  return it.err
+}
+
+// Returns an iterator over all photos matching a search.  args is as for
+// Search; its page argument is ignored.
+func (c *Client) SearchAll(args map[string]string) *PhotoIterator {
+  return newPhotoIterator(func(page int) (*SearchResponse, error) {
+    a := clone(args)
+    a["page"] = strconv.Itoa(page)
+    return c.Search(a)
+  })
+}
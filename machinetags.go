@@ -0,0 +1,244 @@
+package flickgo
+
+import (
+  "errors"
+  "regexp"
+  "strings"
+)
+
+// Values for the machine_tag_mode search argument.
+const (
+  MachineTagModeAny = "any"
+  MachineTagModeAll = "all"
+)
+
+// A machine tag of the form namespace:predicate=value.  See
+// http://www.flickr.com/groups/api/discuss/72157594497877875/.
+type MachineTag struct {
+  Namespace string
+  Predicate string
+  Value     string
+}
+
+// Namespaces and predicates must start with a letter and may only contain
+// letters, digits and underscores.
+var machineTagRE = regexp.MustCompile(`^([a-zA-Z]\w*):([a-zA-Z]\w*)=(.*)$`)
+
+// Parses a machine tag.  Quotes around the whole tag or around the value are
+// removed.
+func ParseMachineTag(s string) (MachineTag, error) {
+  m := machineTagRE.FindStringSubmatch(unquote(strings.TrimSpace(s)))
+  if m == nil || m[3] == "" {
+    return MachineTag{}, errors.New("invalid machine tag: " + s)
+  }
+  return MachineTag{Namespace: m[1], Predicate: m[2], Value: unquote(m[3])}, nil
+}
+
+// Returns s without surrounding double quotes.
+func unquote(s string) string {
+  if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+    return s[1 : len(s)-1]
+  }
+  return s
+}
+
+// Returns the machine tag in Flickr's syntax, quoting the value if it
+// contains whitespace.
+func (m MachineTag) String() string {
+  return m.Namespace + ":" + m.Predicate + "=" + quoteValue(m.Value)
+}
+
+func quoteValue(v string) string {
+  v = strings.Replace(v, `"`, "", -1)
+  if strings.ContainsAny(v, " \t\n") {
+    return `"` + v + `"`
+  }
+  return v
+}
+
+// Returns the machine tag as a search query.  Empty fields act as wildcards,
+// so MachineTag{Namespace: "ourapp"} matches every machine tag in the ourapp
+// namespace.
+func (m MachineTag) query() string {
+  ns, pred := m.Namespace, m.Predicate
+  if ns == "" {
+    ns = "*"
+  }
+  if pred == "" {
+    pred = "*"
+  }
+  switch {
+  case m.Value != "":
+    return ns + ":" + pred + "=" + quoteValue(m.Value)
+  case pred == "*":
+    return ns + ":"
+  }
+  return ns + ":" + pred + "="
+}
+
+// Adds machine_tags and machine_tag_mode arguments to search arguments args.
+// mode is MachineTagModeAny or MachineTagModeAll.  Empty fields of tags act
+// as wildcards.
+func SetMachineTagSearch(args map[string]string, mode string, tags ...MachineTag) {
+  qs := make([]string, len(tags))
+  for i, t := range tags {
+    qs[i] = t.query()
+  }
+  args["machine_tags"] = strings.Join(qs, ",")
+  args["machine_tag_mode"] = mode
+}
+
+// Returns the machine tags of the photo whose namespace and predicate match.
+// Empty namespace or predicate match anything.
+func (r *InfoResponse) MachineTags(namespace, predicate string) []MachineTag {
+  var mts []MachineTag
+  for _, t := range r.Tags {
+    s := t.Raw
+    if s == "" {
+      s = t.Text
+    }
+    m, err := ParseMachineTag(s)
+    if err != nil {
+      continue
+    }
+    if (namespace == "" || m.Namespace == namespace) &&
+      (predicate == "" || m.Predicate == predicate) {
+      mts = append(mts, m)
+    }
+  }
+  return mts
+}
+
+type MachineTagNamespace struct {
+  Name       string `xml:",chardata"`
+  Usage      int    `xml:"usage,attr"`
+  Predicates int    `xml:"predicates,attr"`
+}
+
+type MachineTagPredicate struct {
+  Name       string `xml:",chardata"`
+  Usage      int    `xml:"usage,attr"`
+  Namespaces int    `xml:"namespaces,attr"`
+}
+
+type MachineTagPair struct {
+  Namespace string `xml:"namespace,attr"`
+  Predicate string `xml:"predicate,attr"`
+  Usage     int    `xml:"usage,attr"`
+}
+
+type MachineTagValue struct {
+  Value string `xml:",chardata"`
+  Usage int    `xml:"usage,attr"`
+}
+
+type MachineTagNamespacesResponse struct {
+  Paging
+  Namespaces []MachineTagNamespace `xml:"namespace"`
+}
+
+type MachineTagPredicatesResponse struct {
+  Paging
+  Predicates []MachineTagPredicate `xml:"predicate"`
+}
+
+type MachineTagPairsResponse struct {
+  Paging
+  Pairs []MachineTagPair `xml:"pair"`
+}
+
+type MachineTagValuesResponse struct {
+  Paging
+  Values []MachineTagValue `xml:"value"`
+}
+
+// Returns URL for a flickr.machinetags.* request.  Empty arguments are left
+// out.
+func machineTagsURL(c *Client, method, namespace, predicate string,
+  page, perPage int) string {
+  args := make(map[string]string)
+  if namespace != "" {
+    args["namespace"] = namespace
+  }
+  if predicate != "" {
+    args["predicate"] = predicate
+  }
+  setPage(args, page, perPage)
+  return makeURL(c, "flickr.machinetags."+method, args, true)
+}
+
+// Returns the machine tag namespaces, optionally only those containing
+// predicate.  page and perPage are ignored when zero.
+func (c *Client) GetMachineTagNamespaces(predicate string,
+  page, perPage int) (*MachineTagNamespacesResponse, error) {
+  r := struct {
+    Stat     string                       `xml:"stat,attr"`
+    Err      flickrError                  `xml:"err"`
+    Response MachineTagNamespacesResponse `xml:"namespaces"`
+  }{}
+  u := machineTagsURL(c, "getNamespaces", "", predicate, page, perPage)
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Response, nil
+}
+
+// Returns the machine tag predicates, optionally only those in namespace.
+// page and perPage are ignored when zero.
+func (c *Client) GetMachineTagPredicates(namespace string,
+  page, perPage int) (*MachineTagPredicatesResponse, error) {
+  r := struct {
+    Stat     string                       `xml:"stat,attr"`
+    Err      flickrError                  `xml:"err"`
+    Response MachineTagPredicatesResponse `xml:"predicates"`
+  }{}
+  u := machineTagsURL(c, "getPredicates", namespace, "", page, perPage)
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Response, nil
+}
+
+// Returns the namespace:predicate pairs in use, optionally filtered by
+// namespace and predicate.  page and perPage are ignored when zero.
+func (c *Client) GetMachineTagPairs(namespace, predicate string,
+  page, perPage int) (*MachineTagPairsResponse, error) {
+  r := struct {
+    Stat     string                  `xml:"stat,attr"`
+    Err      flickrError             `xml:"err"`
+    Response MachineTagPairsResponse `xml:"pairs"`
+  }{}
+  u := machineTagsURL(c, "getPairs", namespace, predicate, page, perPage)
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Response, nil
+}
+
+// Returns the values used for namespace:predicate.  page and perPage are
+// ignored when zero.
+func (c *Client) GetMachineTagValues(namespace, predicate string,
+  page, perPage int) (*MachineTagValuesResponse, error) {
+  r := struct {
+    Stat     string                   `xml:"stat,attr"`
+    Err      flickrError              `xml:"err"`
+    Response MachineTagValuesResponse `xml:"values"`
+  }{}
+  u := machineTagsURL(c, "getValues", namespace, predicate, page, perPage)
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Response, nil
+}
@@ -0,0 +1,247 @@
+package flickgo
+
+import (
+  "bytes"
+  "fmt"
+  "net/http"
+  "path/filepath"
+  "strings"
+)
+
+// Content types of the formats accepted by Flickr for upload.  See
+// http://www.flickr.com/help/photos/ and http://www.flickr.com/help/video/.
+var uploadTypes = map[string]bool{
+  "image/jpeg":      true,
+  "image/png":       true,
+  "image/gif":       true,
+  "image/tiff":      true,
+  "image/heic":      true,
+  "image/heif":      true,
+  "video/x-msvideo": true,
+  "video/x-ms-wmv":  true,
+  "video/quicktime": true,
+  "video/mpeg":      true,
+  "video/mp4":       true,
+  "video/3gpp":      true,
+  "video/mp2t":      true,
+  "video/ogg":       true,
+}
+
+// Content types by file extension, used when the file data is inconclusive.
+var contentTypeByExt = map[string]string{
+  ".jpg":  "image/jpeg",
+  ".jpeg": "image/jpeg",
+  ".jpe":  "image/jpeg",
+  ".gif":  "image/gif",
+  ".png":  "image/png",
+  ".tif":  "image/tiff",
+  ".tiff": "image/tiff",
+  ".heic": "image/heic",
+  ".heif": "image/heif",
+  ".avi":  "video/x-msvideo",
+  ".wmv":  "video/x-ms-wmv",
+  ".mov":  "video/quicktime",
+  ".mpg":  "video/mpeg",
+  ".mpeg": "video/mpeg",
+  ".mp4":  "video/mp4",
+  ".m4v":  "video/mp4",
+  ".3gp":  "video/3gpp",
+  ".m2ts": "video/mp2t",
+  ".mts":  "video/mp2t",
+  ".ts":   "video/mp2t",
+  ".ogg":  "video/ogg",
+  ".ogv":  "video/ogg",
+}
+
+// RAW formats stored in a TIFF container, by file extension.
+var tiffRawByExt = map[string]string{
+  ".dng": "image/x-adobe-dng",
+  ".nef": "image/x-nikon-nef",
+  ".nrw": "image/x-nikon-nrw",
+  ".arw": "image/x-sony-arw",
+  ".srw": "image/x-samsung-srw",
+  ".pef": "image/x-pentax-pef",
+  ".rw2": "image/x-panasonic-rw2",
+}
+
+// RAW camera formats.  These are recognised only to give a helpful error, as
+// Flickr doesn't accept them.
+var rawTypes = map[string]bool{
+  "image/x-adobe-dng":     true,
+  "image/x-canon-cr2":     true,
+  "image/x-canon-cr3":     true,
+  "image/x-fuji-raf":      true,
+  "image/x-nikon-nef":     true,
+  "image/x-nikon-nrw":     true,
+  "image/x-olympus-orf":   true,
+  "image/x-panasonic-rw2": true,
+  "image/x-pentax-pef":    true,
+  "image/x-samsung-srw":   true,
+  "image/x-sony-arw":      true,
+}
+
+// Returned when a file is in a format that Flickr does not accept.
+type UnsupportedFormatError struct {
+  Filename string
+
+  // Detected content type; empty if the format could not be identified.
+  ContentType string
+}
+
+func (e *UnsupportedFormatError) Error() string {
+  if e.ContentType == "" {
+    return fmt.Sprintf("%s: unrecognised file format", e.Filename)
+  }
+  if rawTypes[e.ContentType] {
+    return fmt.Sprintf("%s: RAW files (%s) are not accepted by Flickr",
+      e.Filename, e.ContentType)
+  }
+  return fmt.Sprintf("%s: file format %s is not accepted by Flickr",
+    e.Filename, e.ContentType)
+}
+
+// Returns the content type of a photo or video to be uploaded, detected from
+// its data and falling back to filename's extension only if the data is
+// inconclusive.  Returns an *UnsupportedFormatError for formats Flickr does
+// not accept.
+func DetectContentType(filename string, data []byte) (string, error) {
+  ext := strings.ToLower(filepath.Ext(filename))
+  ct := sniff(data, ext)
+  if ct == "" {
+    ct = contentTypeByExt[ext]
+  }
+  if !uploadTypes[ct] {
+    return "", &UnsupportedFormatError{Filename: filename, ContentType: ct}
+  }
+  return ct, nil
+}
+
+// Reports whether contentType is one of the video formats.
+func IsVideo(contentType string) bool {
+  return strings.HasPrefix(contentType, "video/")
+}
+
+// Identifies data by its magic number.  ext is only used to tell apart RAW
+// formats that share the TIFF container.  Returns "" if data doesn't match
+// any known format.
+func sniff(data []byte, ext string) string {
+  has := func(off int, sig string) bool {
+    return len(data) >= off+len(sig) && string(data[off:off+len(sig)]) == sig
+  }
+  switch {
+  case has(0, "II*\x00") || has(0, "MM\x00*"):
+    if has(8, "CR") {
+      return "image/x-canon-cr2"
+    }
+    if raw, ok := tiffRawByExt[ext]; ok {
+      return raw
+    }
+    return "image/tiff"
+  case has(0, "IIRO") || has(0, "IIRS"):
+    return "image/x-olympus-orf"
+  case has(0, "IIU\x00"):
+    return "image/x-panasonic-rw2"
+  case has(0, "FUJIFILMCCD-RAW"):
+    return "image/x-fuji-raf"
+  case has(4, "ftyp"):
+    return isoMediaType(data)
+  case has(4, "moov") || has(4, "mdat") || has(4, "wide") || has(4, "free"):
+    return "video/quicktime"
+  case has(0, "\x30\x26\xb2\x75\x8e\x66\xcf\x11"):
+    return "video/x-ms-wmv"
+  case has(0, "\x00\x00\x01\xba") || has(0, "\x00\x00\x01\xb3"):
+    return "video/mpeg"
+  case has(0, "OggS"):
+    return "video/ogg"
+  }
+
+  switch ct := http.DetectContentType(data); {
+  case ct == "video/avi":
+    return "video/x-msvideo"
+  case ct == "application/octet-stream" && (isMPEGTS(data, 0, 188) || isMPEGTS(data, 4, 192)):
+    // Checked last, as a single sync byte also starts other formats, like
+    // GIF.
+    return "video/mp2t"
+  case ct == "application/octet-stream" || strings.HasPrefix(ct, "text/plain"):
+    return ""
+  default:
+    if i := strings.Index(ct, ";"); i >= 0 {
+      ct = ct[:i]
+    }
+    return ct
+  }
+}
+
+// Reports whether data looks like an MPEG transport stream of packets of
+// size bytes, with their sync byte at offset.  At least two and up to four
+// packets are checked.
+func isMPEGTS(data []byte, offset, size int) bool {
+  n := 0
+  for i := offset; i < len(data) && n < 4; i += size {
+    if data[i] != 0x47 {
+      return false
+    }
+    n++
+  }
+  return n >= 2
+}
+
+// Returns the content type of an ISO base media file (MP4, QuickTime, HEIF
+// and friends) from its major and compatible brands.  Files with brands not
+// known to be video or images, like M4A audio, get a type Flickr doesn't
+// accept.
+func isoMediaType(data []byte) string {
+  size := int(data[0])<<24 | int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+  if size < 16 || size > len(data) {
+    size = 16
+    if len(data) < size {
+      size = len(data)
+    }
+  }
+  var brands [][]byte
+  for i := 8; i+4 <= size; i += 4 {
+    if i == 12 {
+      // Minor version.
+      continue
+    }
+    brands = append(brands, data[i:i+4])
+  }
+  is := func(names ...string) bool {
+    for _, b := range brands {
+      for _, n := range names {
+        if bytes.Equal(b, []byte(n)) {
+          return true
+        }
+      }
+    }
+    return false
+  }
+  var major string
+  if len(brands) > 0 {
+    major = string(brands[0])
+  }
+  switch {
+  case is("crx "):
+    return "image/x-canon-cr3"
+  case major == "avif" || major == "avis":
+    // Also compatible with mif1, so checked before HEIF.
+    return "image/avif"
+  case major == "M4A " || major == "M4B " || major == "M4P " ||
+    major == "F4A " || major == "F4B ":
+    // Also compatible with the MP4 brands.
+    return "audio/mp4"
+  case is("heic", "heix", "hevc", "hevx", "heim", "heis"):
+    return "image/heic"
+  case is("mif1", "msf1"):
+    return "image/heif"
+  case major == "qt  ":
+    return "video/quicktime"
+  case strings.HasPrefix(major, "3g"):
+    return "video/3gpp"
+  case is("isom", "iso2", "iso3", "iso4", "iso5", "iso6", "mp41", "mp42",
+    "avc1", "M4V ", "M4VH", "M4VP", "dash", "mmp4", "MSNV", "f4v "):
+    return "video/mp4"
+  }
+  // Some other kind of ISO base media file.
+  return "application/mp4"
+}
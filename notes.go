@@ -0,0 +1,163 @@
+package flickgo
+
+import (
+  "strconv"
+)
+
+// A rectangle on a photo, in pixels of the 500px "Medium" size of the photo.
+type Rect struct {
+  X int `xml:"x,attr"`
+  Y int `xml:"y,attr"`
+  W int `xml:"w,attr"`
+  H int `xml:"h,attr"`
+}
+
+// Reports whether r is the empty rectangle.
+func (r Rect) IsZero() bool {
+  return r == Rect{}
+}
+
+// Adds the rectangle to args, with keys prefixed by prefix.
+func (r Rect) setArgs(args map[string]string, prefix string) {
+  args[prefix+"x"] = strconv.Itoa(r.X)
+  args[prefix+"y"] = strconv.Itoa(r.Y)
+  args[prefix+"w"] = strconv.Itoa(r.W)
+  args[prefix+"h"] = strconv.Itoa(r.H)
+}
+
+// A note on a photo.
+type Note struct {
+  Rect
+
+  ID         string `xml:"id,attr"`
+  Author     string `xml:"author,attr"`
+  AuthorName string `xml:"authorname,attr"`
+  Text       string `xml:",chardata"`
+}
+
+// Adds a note to a photo and returns the note's ID.
+func (c *Client) AddNote(photoID string, rect Rect, text string) (string, error) {
+  args := map[string]string{
+    "photo_id":  photoID,
+    "note_text": text,
+  }
+  rect.setArgs(args, "note_")
+  req, err := postRequest(c, "flickr.photos.notes.add", args)
+  if err != nil {
+    return "", err
+  }
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    Note struct {
+      ID string `xml:"id,attr"`
+    } `xml:"note"`
+  }{}
+  if err := flickrPost(c, req, &r); err != nil {
+    return "", err
+  }
+  if r.Stat != "ok" {
+    return "", r.Err.Err()
+  }
+  return r.Note.ID, nil
+}
+
+// Changes the position and text of a note.
+func (c *Client) EditNote(noteID string, r Rect, text string) error {
+  args := map[string]string{
+    "note_id":   noteID,
+    "note_text": text,
+  }
+  r.setArgs(args, "note_")
+  return postOK(c, "flickr.photos.notes.edit", args)
+}
+
+// Deletes a note.
+func (c *Client) DeleteNote(noteID string) error {
+  return postOK(c, "flickr.photos.notes.delete", map[string]string{"note_id": noteID})
+}
+
+// A person tagged in a photo.
+type PhotoPerson struct {
+  // Position of the person in the photo; zero if not set.
+  Rect
+
+  NSID       string `xml:"nsid,attr"`
+  UserName   string `xml:"username,attr"`
+  RealName   string `xml:"realname,attr"`
+  IconServer string `xml:"iconserver,attr"`
+  IconFarm   string `xml:"iconfarm,attr"`
+
+  // NSID of the user who tagged the person.
+  AddedBy string `xml:"added_by,attr"`
+}
+
+// Response for flickr.photos.people.getList requests.
+type PhotoPeopleResponse struct {
+  Total       int           `xml:"total,attr"`
+  PhotoWidth  int           `xml:"photo_width,attr"`
+  PhotoHeight int           `xml:"photo_height,attr"`
+  People      []PhotoPerson `xml:"person"`
+}
+
+// Tags a user in a photo.  r is the person's position; a zero r tags the
+// person without a position.
+func (c *Client) AddPerson(photoID, userID string, r Rect) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "user_id":  userID,
+  }
+  if !r.IsZero() {
+    r.setArgs(args, "person_")
+  }
+  return postOK(c, "flickr.photos.people.add", args)
+}
+
+// Removes a user from a photo.
+func (c *Client) DeletePerson(photoID, userID string) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "user_id":  userID,
+  }
+  return postOK(c, "flickr.photos.people.delete", args)
+}
+
+// Removes the position of a user in a photo, keeping the user tagged.
+func (c *Client) DeletePersonCoords(photoID, userID string) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "user_id":  userID,
+  }
+  return postOK(c, "flickr.photos.people.deleteCoords", args)
+}
+
+// Changes the position of a user in a photo.
+func (c *Client) EditPersonCoords(photoID, userID string, r Rect) error {
+  args := map[string]string{
+    "photo_id": photoID,
+    "user_id":  userID,
+  }
+  r.setArgs(args, "person_")
+  return postOK(c, "flickr.photos.people.editCoords", args)
+}
+
+// Returns URL for flickr.photos.people.getList request.
+func getPeopleURL(c *Client, photoID string) string {
+  return singlePhotoURL(c, photoID, "flickr.photos.people.getList")
+}
+
+// Returns the people tagged in a photo.
+func (c *Client) GetPeople(photoID string) (*PhotoPeopleResponse, error) {
+  r := struct {
+    Stat     string              `xml:"stat,attr"`
+    Err      flickrError         `xml:"err"`
+    Response PhotoPeopleResponse `xml:"people"`
+  }{}
+  if err := flickrGet(c, getPeopleURL(c, photoID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Response, nil
+}
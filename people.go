@@ -0,0 +1,276 @@
+package flickgo
+
+import (
+  "fmt"
+  "strconv"
+  "time"
+)
+
+// Upload quota of a user.  See
+// http://www.flickr.com/services/api/flickr.people.getUploadStatus.html.
+type UploadStatus struct {
+  NSID     string `xml:"id,attr"`
+  IsPro    bool   `xml:"ispro,attr"`
+  UserName string `xml:"username"`
+
+  Bandwidth struct {
+    MaxBytes       int64 `xml:"maxbytes,attr"`
+    UsedBytes      int64 `xml:"usedbytes,attr"`
+    RemainingBytes int64 `xml:"remainingbytes,attr"`
+    Unlimited      bool  `xml:"unlimited,attr"`
+  } `xml:"bandwidth"`
+
+  // Maximum size of a single photo.
+  FileSize struct {
+    MaxBytes int64 `xml:"maxbytes,attr"`
+  } `xml:"filesize"`
+
+  // Maximum size of a single video.
+  VideoSize struct {
+    MaxBytes int64 `xml:"maxbytes,attr"`
+  } `xml:"videosize"`
+
+  // Remaining is either a number or "lots".
+  Sets struct {
+    Created   int    `xml:"created,attr"`
+    Remaining string `xml:"remaining,attr"`
+  } `xml:"sets"`
+
+  // Remaining is either a number or "lots".
+  Videos struct {
+    Uploaded  int    `xml:"uploaded,attr"`
+    Remaining string `xml:"remaining,attr"`
+  } `xml:"videos"`
+}
+
+// Returns URL for flickr.people.getUploadStatus request.
+func getUploadStatusURL(c *Client) string {
+  return makeURL(c, "flickr.people.getUploadStatus", map[string]string{}, true)
+}
+
+// Returns the upload quota of the authenticated user.
+func (c *Client) GetUploadStatus() (*UploadStatus, error) {
+  r := struct {
+    Stat   string       `xml:"stat,attr"`
+    Err    flickrError  `xml:"err"`
+    Status UploadStatus `xml:"user"`
+  }{}
+  if err := flickrGet(c, getUploadStatusURL(c), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Status, nil
+}
+
+// Returned by UploadStatus.Check, and by Upload if Client.CheckUploadLimits is
+// set, when a file exceeds the user's upload limits.
+type UploadLimitError struct {
+  Filename string
+
+  // Which limit the file exceeds.
+  Reason string
+}
+
+func (e *UploadLimitError) Error() string {
+  return e.Filename + ": " + e.Reason
+}
+
+// Checks whether a file can be uploaded within the limits in s.  Returns an
+// error describing the first limit the file exceeds, if any.
+func (s *UploadStatus) Check(filename string, data []byte) error {
+  ct, err := DetectContentType(filename, data)
+  if err != nil {
+    return err
+  }
+  size := int64(len(data))
+  if IsVideo(ct) {
+    if s.Videos.Remaining != "" && s.Videos.Remaining != "lots" {
+      if n, err := strconv.Atoi(s.Videos.Remaining); err == nil && n <= 0 {
+        return &UploadLimitError{filename, "no video uploads remaining"}
+      }
+    }
+    if s.VideoSize.MaxBytes > 0 && size > s.VideoSize.MaxBytes {
+      return &UploadLimitError{filename, fmt.Sprintf(
+        "video is %d bytes, maximum is %d", size, s.VideoSize.MaxBytes)}
+    }
+  } else if s.FileSize.MaxBytes > 0 && size > s.FileSize.MaxBytes {
+    return &UploadLimitError{filename, fmt.Sprintf(
+      "photo is %d bytes, maximum is %d", size, s.FileSize.MaxBytes)}
+  }
+  if !s.Bandwidth.Unlimited && s.Bandwidth.MaxBytes > 0 &&
+    size > s.Bandwidth.RemainingBytes {
+    return &UploadLimitError{filename, fmt.Sprintf(
+      "file is %d bytes, only %d bytes of upload bandwidth remaining", size,
+      s.Bandwidth.RemainingBytes)}
+  }
+  return nil
+}
+
+// How long the upload limits fetched for Client.CheckUploadLimits are used
+// before being fetched again.
+const uploadStatusTTL = time.Hour
+
+// Checks a file against the upload limits of the user of c.AuthToken and
+// takes it off the remaining bandwidth and videos.  The limits are fetched if
+// missing, stale or fetched for another token.  release gives back what was
+// taken, for a failed upload.
+func (c *Client) reserveUpload(name string, data []byte) (release func(), err error) {
+  c.statusMu.Lock()
+  defer c.statusMu.Unlock()
+  if c.uploadStatus == nil || c.statusToken != c.AuthToken ||
+    time.Since(c.statusTime) > uploadStatusTTL {
+    s, sErr := c.GetUploadStatus()
+    if sErr != nil {
+      return nil, wrapErr("getting upload status failed", sErr)
+    }
+    c.uploadStatus, c.statusToken, c.statusTime = s, c.AuthToken, time.Now()
+  }
+  s := c.uploadStatus
+  if err := s.Check(name, data); err != nil {
+    return nil, err
+  }
+  size, videos := int64(len(data)), 0
+  if ct, _ := DetectContentType(name, data); IsVideo(ct) {
+    videos = 1
+  }
+  s.use(size, videos)
+  return func() {
+    c.statusMu.Lock()
+    defer c.statusMu.Unlock()
+    s.use(-size, -videos)
+  }, nil
+}
+
+// Counts size bytes and the given number of videos as uploaded.
+func (s *UploadStatus) use(size int64, videos int) {
+  s.Bandwidth.UsedBytes += size
+  s.Bandwidth.RemainingBytes -= size
+  s.Videos.Uploaded += videos
+  if n, err := strconv.Atoi(s.Videos.Remaining); err == nil {
+    s.Videos.Remaining = strconv.Itoa(n - videos)
+  }
+}
+
+// A user as found in responses where the user name is an element rather
+// than an attribute.
+type userElem struct {
+  User
+
+  ID       string `xml:"id,attr"`
+  UserName string `xml:"username"`
+}
+
+// Returns the User of u.
+func (u *userElem) user() *User {
+  r := u.User
+  r.UserName = u.UserName
+  if r.NSID == "" {
+    r.NSID = u.ID
+  }
+  return &r
+}
+
+// Sends a people or urls request that returns a user element.
+func getUser(c *Client, u string) (*User, error) {
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    User userElem    `xml:"user"`
+  }{}
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.User.user(), nil
+}
+
+// Returns URL for flickr.people.findByUsername request.
+func findByUsernameURL(c *Client, username string) string {
+  args := make(map[string]string)
+  args["username"] = username
+  return makeURL(c, "flickr.people.findByUsername", args, true)
+}
+
+// Returns the user with the specified user name.  Only UserName and NSID are
+// set.
+func (c *Client) FindUserByUsername(username string) (*User, error) {
+  return getUser(c, findByUsernameURL(c, username))
+}
+
+// Returns URL for flickr.people.findByEmail request.
+func findByEmailURL(c *Client, email string) string {
+  args := make(map[string]string)
+  args["find_email"] = email
+  return makeURL(c, "flickr.people.findByEmail", args, true)
+}
+
+// Returns the user with the specified email address.  Only UserName and NSID
+// are set.
+func (c *Client) FindUserByEmail(email string) (*User, error) {
+  return getUser(c, findByEmailURL(c, email))
+}
+
+// Returns URL for flickr.urls.lookupUser request.
+func lookupUserURL(c *Client, profileURL string) string {
+  args := make(map[string]string)
+  args["url"] = profileURL
+  return makeURL(c, "flickr.urls.lookupUser", args, true)
+}
+
+// Returns the user whose photos or profile page is at profileURL.  Only
+// UserName and NSID are set.
+func (c *Client) LookupUser(profileURL string) (*User, error) {
+  return getUser(c, lookupUserURL(c, profileURL))
+}
+
+// Returns URL for flickr.people.getInfo request.
+func getPersonInfoURL(c *Client, userID string) string {
+  args := make(map[string]string)
+  args["user_id"] = userID
+  return makeURL(c, "flickr.people.getInfo", args, true)
+}
+
+// Returns the profile of a user.
+func (c *Client) GetPersonInfo(userID string) (*User, error) {
+  r := struct {
+    Stat   string      `xml:"stat,attr"`
+    Err    flickrError `xml:"err"`
+    Person userElem    `xml:"person"`
+  }{}
+  if err := flickrGet(c, getPersonInfoURL(c, userID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Person.user(), nil
+}
+
+// Returns URL for flickr.urls.getUserProfile request.
+func getUserProfileURL(c *Client, userID string) string {
+  args := make(map[string]string)
+  args["user_id"] = userID
+  return makeURL(c, "flickr.urls.getUserProfile", args, true)
+}
+
+// Returns the URL of a user's profile page.
+func (c *Client) GetUserProfileURL(userID string) (string, error) {
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    User struct {
+      URL string `xml:"url,attr"`
+    } `xml:"user"`
+  }{}
+  if err := flickrGet(c, getUserProfileURL(c, userID), &r); err != nil {
+    return "", err
+  }
+  if r.Stat != "ok" {
+    return "", r.Err.Err()
+  }
+  return r.User.URL, nil
+}
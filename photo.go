@@ -20,6 +20,53 @@ const (
   SizeOriginal    = "o"
 )
 
+// Content types of a photo, for use with Client.SetContentType.
+type ContentType int
+
+const (
+  ContentPhoto      ContentType = 1
+  ContentScreenshot ContentType = 2
+  ContentOther      ContentType = 3
+)
+
+// Safety levels of a photo, for use with Client.SetSafetyLevel.
+type SafetyLevel int
+
+const (
+  SafetySafe       SafetyLevel = 1
+  SafetyModerate   SafetyLevel = 2
+  SafetyRestricted SafetyLevel = 3
+)
+
+// Precision of a photo's taken date.  See
+// http://www.flickr.com/services/api/misc.dates.html.
+type Granularity int
+
+const (
+  GranularitySecond Granularity = 0
+  GranularityMonth  Granularity = 4
+  GranularityYear   Granularity = 6
+  GranularityCirca  Granularity = 8
+)
+
+// Who may comment on or add metadata to a photo.
+type PermLevel int
+
+const (
+  PermNobody        PermLevel = 0
+  PermFriendsFamily PermLevel = 1
+  PermContacts      PermLevel = 2
+  PermEveryone      PermLevel = 3
+)
+
+// Paging information of list responses.
+type Paging struct {
+  Page    int `xml:"page,attr"`
+  Pages   int `xml:"pages,attr"`
+  PerPage int `xml:"perpage,attr"`
+  Total   int `xml:"total,attr"`
+}
+
 // Response for photo search requests.
 type SearchResponse struct {
   Page    string        `xml:"page,attr"`
@@ -50,6 +97,14 @@ type Visibility struct {
   IsFamily bool `xml:"isfamily,attr"`
 }
 
+// Permissions of a photo, for use with Client.SetPerms.
+type Perms struct {
+  Visibility
+
+  PermComment PermLevel `xml:"permcomment,attr"`
+  PermAddMeta PermLevel `xml:"permaddmeta,attr"`
+}
+
 type Dates struct {
   Posted           string `xml:"posted,attr"` // Unix timestamp
   Taken            string `xml:"taken,attr"`
@@ -58,8 +113,16 @@ type Dates struct {
 }
 
 type Tag struct {
-  ID   string `xml:"id,attr"`
-  Text string `xml:",chardata"`
+  ID string `xml:"id,attr"`
+
+  // NSID and name of the user who added the tag.
+  Author     string `xml:"author,attr"`
+  AuthorName string `xml:"authorname,attr"`
+
+  // Tag as entered by its author; Text is its normalised form.
+  Raw        string `xml:"raw,attr"`
+  MachineTag bool   `xml:"machine_tag,attr"`
+  Text       string `xml:",chardata"`
 }
 
 type Url struct {
@@ -91,6 +154,43 @@ func (d *Dates) LastupdateTime() time.Time {
 type User struct {
   UserName string `xml:"username,attr"`
   NSID     string `xml:"nsid,attr"`
+
+  // The remaining fields are only set by GetPersonInfo.
+  RealName   string `xml:"realname"`
+  Location   string `xml:"location"`
+  PathAlias  string `xml:"path_alias,attr"`
+  IsPro      bool   `xml:"ispro,attr"`
+  PhotosURL  string `xml:"photosurl"`
+  ProfileURL string `xml:"profileurl"`
+
+  // Location of the buddy icon; see BuddyIconURL.
+  IconServer string `xml:"iconserver,attr"`
+  IconFarm   string `xml:"iconfarm,attr"`
+
+  Photos UserPhotos `xml:"photos"`
+}
+
+// Photo statistics of a user.
+type UserPhotos struct {
+  Count          int    `xml:"count"`
+  FirstDate      string `xml:"firstdate"` // Unix timestamp
+  FirstDateTaken string `xml:"firstdatetaken"`
+}
+
+// Returns the time of the user's first upload, or the zero time if unknown.
+func (p *UserPhotos) FirstTime() time.Time {
+  return optionalTime(p.FirstDate)
+}
+
+// Returns the URL of the user's buddy icon, or of Flickr's default icon if
+// the user has none.  See
+// http://www.flickr.com/services/api/misc.buddyicons.html.
+func (u *User) BuddyIconURL() string {
+  if u.IconServer == "" || u.IconServer == "0" {
+    return "http://www.flickr.com/images/buddyicon.gif"
+  }
+  return fmt.Sprintf("http://farm%s.static.flickr.com/%s/buddyicons/%s.jpg",
+    u.IconFarm, u.IconServer, u.NSID)
 }
 
 type Photo struct {
@@ -106,10 +206,37 @@ type SearchPhoto struct {
 
   Owner    string `xml:"owner,attr"`
   IsPublic string `xml:"ispublic,attr"`
+  IsFriend string `xml:"isfriend,attr"`
+  IsFamily string `xml:"isfamily,attr"`
   Width_T  string `xml:"width_t,attr"`
   Height_T string `xml:"height_t,attr"`
   Title    string `xml:"title,attr"`
 
+  // "photo" or "video"; requires the media extra.
+  Media string `xml:"media,attr"`
+
+  // Only set for photos listed in a photo set or gallery.
+  IsPrimary bool `xml:"isprimary,attr"`
+
+  // Require the date_upload, date_taken and last_update extras.
+  DateUpload string `xml:"dateupload,attr"` // Unix timestamp
+  DateTaken  string `xml:"datetaken,attr"`
+  LastUpdate string `xml:"lastupdate,attr"` // Unix timestamp
+
+  // Only set for photos listed in favorites.
+  DateFaved string `xml:"date_faved,attr"` // Unix timestamp
+
+  // Only set for photos listed in a gallery: the curator's comment.
+  GalleryComment string `xml:"comment"`
+
+  // Require the geo extra.  Accuracy is zero for photos with no location.
+  Latitude   float64    `xml:"latitude,attr"`
+  Longitude  float64    `xml:"longitude,attr"`
+  Accuracy   int        `xml:"accuracy,attr"`
+  GeoContext GeoContext `xml:"context,attr"`
+  PlaceID    string     `xml:"place_id,attr"`
+  WOEID      string     `xml:"woeid,attr"`
+
   Width  int `xml:"o_width,attr"`
   Height int `xml:"o_height,attr"`
 
@@ -117,6 +244,28 @@ type SearchPhoto struct {
   Ratio float64
 }
 
+// Returns the time the photo was last changed, or the zero time if the
+// last_update extra wasn't requested.
+func (p *SearchPhoto) LastUpdateTime() time.Time {
+  return optionalTime(p.LastUpdate)
+}
+
+// Reports whether the photo has a location; requires the geo extra.
+func (p *SearchPhoto) HasLocation() bool {
+  return p.Accuracy != 0
+}
+
+// Returns the URL of the photo's page on Flickr.
+func (p *SearchPhoto) PageURL() string {
+  return fmt.Sprintf("http://www.flickr.com/photos/%s/%s/", p.Owner, p.ID)
+}
+
+// Returns the time the photo was marked as favorite, or the zero time if
+// the photo isn't from a favorites list.
+func (p *SearchPhoto) FavedTime() time.Time {
+  return optionalTime(p.DateFaved)
+}
+
 type InfoResponse struct {
   Photo
 
@@ -128,6 +277,13 @@ type InfoResponse struct {
   Tags        []Tag      `xml:"tags>tag"`
   Urls        []Url      `xml:"urls>url"`
   Title       string     `xml:"title"`
+  Notes       []Note     `xml:"notes>note"`
+  People      struct {
+    // Whether people are tagged in the photo; see GetPeople.
+    HasPeople bool `xml:"haspeople,attr"`
+  } `xml:"people"`
+  // Nil if the photo has no location, or the caller may not see it.
+  Location *Location `xml:"location"`
 }
 
 // Returns the URL to this photo in the specified size.
@@ -140,8 +296,75 @@ func (p *Photo) URL(size string) string {
     p.Farm, p.Server, p.ID, p.Secret, size)
 }
 
+// Returns the time for a Unix timestamp, or the zero time if source is empty,
+// "0" or not a timestamp.
+func optionalTime(source string) time.Time {
+  unix, err := strconv.ParseInt(source, 10, 64)
+  if err != nil || unix == 0 {
+    return time.Time{}
+  }
+  return time.Unix(unix, 0)
+}
+
+// A photo set (album).
 type PhotoSet struct {
   ID          string `xml:"id,attr"`
   Title       string `xml:"title"`
   Description string `xml:"description"`
+
+  // Owner's NSID and user name.  Only set by GetSetInfo.
+  Owner     string `xml:"owner,attr"`
+  OwnerName string `xml:"username,attr"`
+
+  // ID of the primary (cover) photo, and its details for building URLs.
+  Primary string `xml:"primary,attr"`
+  Secret  string `xml:"secret,attr"`
+  Server  string `xml:"server,attr"`
+  Farm    string `xml:"farm,attr"`
+
+  Photos        int `xml:"photos,attr"`
+  Videos        int `xml:"videos,attr"`
+  CountViews    int `xml:"count_views,attr"`
+  CountComments int `xml:"count_comments,attr"`
+
+  CanComment bool `xml:"can_comment,attr"`
+
+  // Whether the set is visible to the calling user.
+  CanSee bool `xml:"visibility_can_see_set,attr"`
+
+  DateCreate string `xml:"date_create,attr"` // Unix timestamp
+  DateUpdate string `xml:"date_update,attr"` // Unix timestamp
+
+  // Extras of the primary photo, if requested with primary_photo_extras.
+  PrimaryPhotoExtras *SearchPhoto `xml:"primary_photo_extras"`
+}
+
+// Returns the set's primary photo, whose URL method gives the cover image.
+func (s *PhotoSet) PrimaryPhoto() *Photo {
+  return &Photo{ID: s.Primary, Secret: s.Secret, Server: s.Server, Farm: s.Farm}
+}
+
+func (s *PhotoSet) CreateTime() time.Time {
+  return optionalTime(s.DateCreate)
+}
+
+func (s *PhotoSet) UpdateTime() time.Time {
+  return optionalTime(s.DateUpdate)
+}
+
+// A neighbour of a photo in a photo set, pool or other list.  ID is "0" if
+// there is no such photo.
+type ContextPhoto struct {
+  Photo
+
+  Title    string `xml:"title,attr"`
+  PageURL  string `xml:"url,attr"`
+  ThumbURL string `xml:"thumb,attr"`
+  Media    string `xml:"media,attr"`
+}
+
+// Photos before and after a photo in a list.
+type PhotoContext struct {
+  Prev ContextPhoto `xml:"prevphoto"`
+  Next ContextPhoto `xml:"nextphoto"`
 }
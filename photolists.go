@@ -0,0 +1,116 @@
+package flickgo
+
+import (
+  "strconv"
+)
+
+// Calls a Flickr method that returns a page of photos in the same format as
+// flickr.photos.search.
+func getPhotoList(c *Client, method string, args map[string]string) (*SearchResponse, error) {
+  r := struct {
+    Stat   string         `xml:"stat,attr"`
+    Err    flickrError    `xml:"err"`
+    Photos SearchResponse `xml:"photos"`
+  }{}
+  if err := flickrGet(c, makeURL(c, method, args, true), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  setRatios(r.Photos.Photos)
+  return &r.Photos, nil
+}
+
+// Returns an iterator over all pages of a photo list method.
+func photoListIterator(c *Client, method string, args map[string]string) *PhotoIterator {
+  return newPhotoIterator(func(page int) (*SearchResponse, error) {
+    a := clone(args)
+    a["page"] = strconv.Itoa(page)
+    return getPhotoList(c, method, a)
+  })
+}
+
+// Returns a copy of args with key set to value.
+func withArg(args map[string]string, key, value string) map[string]string {
+  a := clone(args)
+  a[key] = value
+  return a
+}
+
+// Returns a page of photos of a user, including private ones if the caller
+// may see them.  Use "me" as userID for the calling user.  args may contain
+// the optional arguments described in
+// http://www.flickr.com/services/api/flickr.people.getPhotos.html, like
+// extras, privacy_filter, min_upload_date, page and per_page.
+func (c *Client) GetUserPhotos(userID string, args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.people.getPhotos", withArg(args, "user_id", userID))
+}
+
+// Returns an iterator over all photos of a user; see GetUserPhotos.
+func (c *Client) GetUserPhotosAll(userID string, args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.people.getPhotos", withArg(args, "user_id", userID))
+}
+
+// Returns a page of public photos of a user.  args may contain extras,
+// safe_search, page and per_page.
+func (c *Client) GetPublicPhotos(userID string, args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.people.getPublicPhotos", withArg(args, "user_id", userID))
+}
+
+// Returns an iterator over all public photos of a user.
+func (c *Client) GetPublicPhotosAll(userID string, args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.people.getPublicPhotos", withArg(args, "user_id", userID))
+}
+
+// Returns a page of photos in which a user has been tagged.  args may contain
+// owner_id, extras, page and per_page.
+func (c *Client) GetPhotosOf(userID string, args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.people.getPhotosOf", withArg(args, "user_id", userID))
+}
+
+// Returns an iterator over all photos in which a user has been tagged.
+func (c *Client) GetPhotosOfAll(userID string, args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.people.getPhotosOf", withArg(args, "user_id", userID))
+}
+
+// Returns a page of the calling user's photos that are not in any set.  args
+// may contain the date and privacy filters, media, extras, page and per_page.
+func (c *Client) GetNotInSet(args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.photos.getNotInSet", args)
+}
+
+// Returns an iterator over all of the calling user's photos not in any set.
+func (c *Client) GetNotInSetAll(args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.photos.getNotInSet", args)
+}
+
+// Returns a page of the calling user's photos that have no tags.
+func (c *Client) GetUntagged(args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.photos.getUntagged", args)
+}
+
+// Returns an iterator over all of the calling user's photos with no tags.
+func (c *Client) GetUntaggedAll(args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.photos.getUntagged", args)
+}
+
+// Returns a page of the calling user's photos that have a location.
+func (c *Client) GetWithGeoData(args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.photos.getWithGeoData", args)
+}
+
+// Returns an iterator over all of the calling user's photos with a location.
+func (c *Client) GetWithGeoDataAll(args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.photos.getWithGeoData", args)
+}
+
+// Returns a page of the calling user's photos that have no location.
+func (c *Client) GetWithoutGeoData(args map[string]string) (*SearchResponse, error) {
+  return getPhotoList(c, "flickr.photos.getWithoutGeoData", args)
+}
+
+// Returns an iterator over all of the calling user's photos with no location.
+func (c *Client) GetWithoutGeoDataAll(args map[string]string) *PhotoIterator {
+  return photoListIterator(c, "flickr.photos.getWithoutGeoData", args)
+}
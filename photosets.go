@@ -0,0 +1,259 @@
+package flickgo
+
+import (
+  "strconv"
+  "strings"
+)
+
+// Creates a new photo set with primaryPhotoID as its cover and returns the
+// ID of the set.
+func (c *Client) CreateSet(title, description, primaryPhotoID string) (string, error) {
+  args := map[string]string{
+    "title":            title,
+    "description":      description,
+    "primary_photo_id": primaryPhotoID,
+  }
+  req, err := postRequest(c, "flickr.photosets.create", args)
+  if err != nil {
+    return "", err
+  }
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    Set  struct {
+      ID string `xml:"id,attr"`
+    } `xml:"photoset"`
+  }{}
+  if err := flickrPost(c, req, &r); err != nil {
+    return "", err
+  }
+  if r.Stat != "ok" {
+    return "", r.Err.Err()
+  }
+  return r.Set.ID, nil
+}
+
+// Deletes a photo set.  The photos in it are not deleted.
+func (c *Client) DeleteSet(setID string) error {
+  return postOK(c, "flickr.photosets.delete",
+    map[string]string{"photoset_id": setID})
+}
+
+// Sets the title and description of a photo set.
+func (c *Client) EditSetMeta(setID, title, description string) error {
+  args := map[string]string{
+    "photoset_id": setID,
+    "title":       title,
+    "description": description,
+  }
+  return postOK(c, "flickr.photosets.editMeta", args)
+}
+
+// Replaces the photos of a set with photoIDs, in that order.  primaryPhotoID
+// must be one of photoIDs.
+func (c *Client) EditSetPhotos(setID, primaryPhotoID string, photoIDs []string) error {
+  args := map[string]string{
+    "photoset_id":      setID,
+    "primary_photo_id": primaryPhotoID,
+    "photo_ids":        strings.Join(photoIDs, ","),
+  }
+  return postOK(c, "flickr.photosets.editPhotos", args)
+}
+
+// Removes a photo from a photo set.
+func (c *Client) RemovePhotoFromSet(photoID, setID string) error {
+  args := map[string]string{
+    "photo_id":    photoID,
+    "photoset_id": setID,
+  }
+  return postOK(c, "flickr.photosets.removePhoto", args)
+}
+
+// Removes several photos from a photo set.
+func (c *Client) RemovePhotosFromSet(setID string, photoIDs []string) error {
+  args := map[string]string{
+    "photoset_id": setID,
+    "photo_ids":   strings.Join(photoIDs, ","),
+  }
+  return postOK(c, "flickr.photosets.removePhotos", args)
+}
+
+// Moves photoIDs to the start of the set, in that order.  Photos of the set
+// not in photoIDs keep their relative order after them.
+func (c *Client) ReorderSetPhotos(setID string, photoIDs []string) error {
+  args := map[string]string{
+    "photoset_id": setID,
+    "photo_ids":   strings.Join(photoIDs, ","),
+  }
+  return postOK(c, "flickr.photosets.reorderPhotos", args)
+}
+
+// Sets the primary (cover) photo of a set.
+func (c *Client) SetPrimaryPhoto(setID, photoID string) error {
+  args := map[string]string{
+    "photoset_id": setID,
+    "photo_id":    photoID,
+  }
+  return postOK(c, "flickr.photosets.setPrimaryPhoto", args)
+}
+
+// Sets the order of the calling user's photo sets.  Sets not in setIDs are
+// placed after them.
+func (c *Client) OrderSets(setIDs []string) error {
+  return postOK(c, "flickr.photosets.orderSets",
+    map[string]string{"photoset_ids": strings.Join(setIDs, ",")})
+}
+
+// Returns URL for flickr.photosets.getInfo request.
+func getSetInfoURL(c *Client, setID string) string {
+  args := make(map[string]string)
+  args["photoset_id"] = setID
+  return makeURL(c, "flickr.photosets.getInfo", args, true)
+}
+
+// Returns the details of a photo set.
+func (c *Client) GetSetInfo(setID string) (*PhotoSet, error) {
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    Set  PhotoSet    `xml:"photoset"`
+  }{}
+  if err := flickrGet(c, getSetInfoURL(c, setID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Set, nil
+}
+
+// Returns URL for flickr.photosets.getContext request.
+func getSetContextURL(c *Client, photoID, setID string) string {
+  args := make(map[string]string)
+  args["photo_id"] = photoID
+  args["photoset_id"] = setID
+  return makeURL(c, "flickr.photosets.getContext", args, true)
+}
+
+// Returns the photos before and after photoID in a photo set.
+func (c *Client) GetSetContext(photoID, setID string) (*PhotoContext, error) {
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    PhotoContext
+  }{}
+  if err := flickrGet(c, getSetContextURL(c, photoID, setID), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.PhotoContext, nil
+}
+
+// Values for the media argument of photo listing methods.
+const (
+  MediaAll    = "all"
+  MediaPhotos = "photos"
+  MediaVideos = "videos"
+)
+
+// Values for the privacy_filter argument of photo listing methods.
+const (
+  PrivacyPublic        = "1"
+  PrivacyFriends       = "2"
+  PrivacyFamily        = "3"
+  PrivacyFriendsFamily = "4"
+  PrivacyPrivate       = "5"
+)
+
+// Response for flickr.photosets.getList requests.
+type PhotoSetsResponse struct {
+  Paging
+
+  CanCreate bool       `xml:"cancreate,attr"`
+  Sets      []PhotoSet `xml:"photoset"`
+}
+
+// Returns URL for flickr.photosets.getList request with extra arguments.
+func getSetListURL(c *Client, userID string, args map[string]string) string {
+  a := clone(args)
+  a["user_id"] = userID
+  return makeURL(c, "flickr.photosets.getList", a, true)
+}
+
+// Returns a page of photo sets of the specified user.  args may contain page,
+// per_page and primary_photo_extras as described in
+// http://www.flickr.com/services/api/flickr.photosets.getList.html.
+func (c *Client) GetSetList(userID string, args map[string]string) (*PhotoSetsResponse, error) {
+  r := struct {
+    Stat     string            `xml:"stat,attr"`
+    Err      flickrError       `xml:"err"`
+    Response PhotoSetsResponse `xml:"photosets"`
+  }{}
+  if err := flickrGet(c, getSetListURL(c, userID, args), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return &r.Response, nil
+}
+
+// Response for flickr.photosets.getPhotos requests.
+type SetPhotosResponse struct {
+  SearchResponse
+
+  ID        string `xml:"id,attr"`
+  Primary   string `xml:"primary,attr"`
+  Owner     string `xml:"owner,attr"`
+  OwnerName string `xml:"ownername,attr"`
+  Title     string `xml:"title,attr"`
+}
+
+// Returns URL for flickr.photosets.getPhotos request.
+func getSetPhotosURL(c *Client, setID string, args map[string]string) string {
+  a := clone(args)
+  a["photoset_id"] = setID
+  return makeURL(c, "flickr.photosets.getPhotos", a, true)
+}
+
+// Returns a page of the photos in a set.  args may contain extras,
+// privacy_filter, media, page and per_page as described in
+// http://www.flickr.com/services/api/flickr.photosets.getPhotos.html.
+func (c *Client) GetSetPhotos(setID string, args map[string]string) (*SetPhotosResponse, error) {
+  r := struct {
+    Stat     string            `xml:"stat,attr"`
+    Err      flickrError       `xml:"err"`
+    Response SetPhotosResponse `xml:"photoset"`
+  }{}
+  if err := flickrGet(c, getSetPhotosURL(c, setID, args), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+
+  // Photos in a set don't carry their owner.
+  for i := range r.Response.Photos {
+    if r.Response.Photos[i].Owner == "" {
+      r.Response.Photos[i].Owner = r.Response.Owner
+    }
+  }
+  setRatios(r.Response.Photos)
+  return &r.Response, nil
+}
+
+// Returns an iterator over all photos in a set.  args is as for GetSetPhotos;
+// its page argument is ignored.
+func (c *Client) GetSetPhotosAll(setID string, args map[string]string) *PhotoIterator {
+  return newPhotoIterator(func(page int) (*SearchResponse, error) {
+    a := clone(args)
+    a["page"] = strconv.Itoa(page)
+    r, err := c.GetSetPhotos(setID, a)
+    if err != nil {
+      return nil, err
+    }
+    return &r.SearchResponse, nil
+  })
+}
@@ -0,0 +1,247 @@
+package flickgo
+
+import (
+  "strconv"
+  "strings"
+  "time"
+)
+
+// Kind of place in the Flickr places hierarchy.
+type PlaceTypeID int
+
+const (
+  PlaceLocality      PlaceTypeID = 7
+  PlaceRegion        PlaceTypeID = 8
+  PlaceCounty        PlaceTypeID = 9
+  PlaceCountry       PlaceTypeID = 12
+  PlaceNeighbourhood PlaceTypeID = 22
+  PlaceContinent     PlaceTypeID = 29
+)
+
+// Outline of a place.
+type ShapeData struct {
+  Created     string  `xml:"created,attr"` // Unix timestamp
+  Alpha       float64 `xml:"alpha,attr"`
+  Points      int     `xml:"count_points,attr"`
+  Edges       int     `xml:"count_edges,attr"`
+  IsDonutHole bool    `xml:"is_donuthole,attr"`
+
+  // Outlines as space separated "lat,lon" pairs.
+  Polylines []string `xml:"polylines>polyline"`
+
+  // URL of a zipped shapefile of the outline.
+  ShapeFile string `xml:"urls>shapefile"`
+}
+
+func (s *ShapeData) CreateTime() time.Time {
+  return optionalTime(s.Created)
+}
+
+// Returns the outlines of the shape as lists of [lat, lon] pairs.  Malformed
+// pairs are skipped.
+func (s *ShapeData) Coordinates() [][][2]float64 {
+  lines := make([][][2]float64, len(s.Polylines))
+  for i, l := range s.Polylines {
+    for _, pair := range strings.Fields(l) {
+      ll := strings.SplitN(pair, ",", 2)
+      if len(ll) != 2 {
+        continue
+      }
+      lat, latErr := strconv.ParseFloat(ll[0], 64)
+      lon, lonErr := strconv.ParseFloat(ll[1], 64)
+      if latErr != nil || lonErr != nil {
+        continue
+      }
+      lines[i] = append(lines[i], [2]float64{lat, lon})
+    }
+  }
+  return lines
+}
+
+// A place, like a neighbourhood, city or country.
+type Place struct {
+  PlaceID     string      `xml:"place_id,attr"`
+  WOEID       string      `xml:"woeid,attr"`
+  Latitude    float64     `xml:"latitude,attr"`
+  Longitude   float64     `xml:"longitude,attr"`
+  PlaceURL    string      `xml:"place_url,attr"`
+  PlaceType   string      `xml:"place_type,attr"`
+  PlaceTypeID PlaceTypeID `xml:"place_type_id,attr"`
+  Timezone    string      `xml:"timezone,attr"`
+  Name        string      `xml:"name,attr"`
+
+  // Number of photos at the place; only set by methods listing places with
+  // photos.
+  PhotoCount int `xml:"photo_count,attr"`
+
+  // The places containing this one.  Only set by GetPlaceInfo and
+  // GetPlaceInfoByURL.
+  Neighbourhood LocationPlace `xml:"neighbourhood"`
+  Locality      LocationPlace `xml:"locality"`
+  County        LocationPlace `xml:"county"`
+  Region        LocationPlace `xml:"region"`
+  Country       LocationPlace `xml:"country"`
+
+  HasShapeData bool       `xml:"has_shapedata,attr"`
+  ShapeData    *ShapeData `xml:"shapedata"`
+}
+
+// A place as found in responses where the name may be the element's text
+// rather than an attribute.
+type placeElem struct {
+  Place
+
+  Text string `xml:",chardata"`
+}
+
+// Returns the Place of p.
+func (p *placeElem) place() Place {
+  r := p.Place
+  if r.Name == "" {
+    r.Name = strings.TrimSpace(p.Text)
+  }
+  return r
+}
+
+// Adds a place_id argument, or a woe_id one if p has no place ID, to search
+// arguments args, to restrict a search to photos taken in p.
+func SetPlaceSearch(args map[string]string, p *Place) {
+  if p.PlaceID != "" {
+    args["place_id"] = p.PlaceID
+  } else {
+    args["woe_id"] = p.WOEID
+  }
+}
+
+// Returns a copy of args with place_id or woe_id set, if not empty.
+func placeArgs(args map[string]string, placeID, woeID string) map[string]string {
+  a := clone(args)
+  if placeID != "" {
+    a["place_id"] = placeID
+  }
+  if woeID != "" {
+    a["woe_id"] = woeID
+  }
+  return a
+}
+
+// Sends a places request that returns a list of places.
+func getPlaces(c *Client, method string, args map[string]string) ([]Place, error) {
+  r := struct {
+    Stat   string      `xml:"stat,attr"`
+    Err    flickrError `xml:"err"`
+    Places []placeElem `xml:"places>place"`
+  }{}
+  if err := flickrGet(c, makeURL(c, method, args, true), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  places := make([]Place, len(r.Places))
+  for i := range r.Places {
+    places[i] = r.Places[i].place()
+  }
+  return places, nil
+}
+
+// Sends a places request that returns a single place.
+func getPlace(c *Client, method string, args map[string]string) (*Place, error) {
+  r := struct {
+    Stat  string      `xml:"stat,attr"`
+    Err   flickrError `xml:"err"`
+    Place placeElem   `xml:"place"`
+  }{}
+  if err := flickrGet(c, makeURL(c, method, args, true), &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  p := r.Place.place()
+  return &p, nil
+}
+
+// Returns the places matching a free text query, like "Paris, France".
+func (c *Client) FindPlaces(query string) ([]Place, error) {
+  return getPlaces(c, "flickr.places.find", map[string]string{"query": query})
+}
+
+// Returns the place containing a location at the level given by accuracy,
+// from AccuracyWorld to AccuracyStreet.  accuracy is left to Flickr's default
+// when zero.
+func (c *Client) FindPlacesByLatLon(lat, lon float64, accuracy int) ([]Place, error) {
+  return getPlaces(c, "flickr.places.findByLatLon",
+    locationArgs(nil, lat, lon, accuracy))
+}
+
+// Returns a place, with its hierarchy and outline, given its place ID or
+// WOE ID.  Only one of placeID and woeID should be set.
+func (c *Client) GetPlaceInfo(placeID, woeID string) (*Place, error) {
+  return getPlace(c, "flickr.places.getInfo", placeArgs(nil, placeID, woeID))
+}
+
+// Returns a place given its Flickr URL, like "/Canada/Quebec/Montreal".
+func (c *Client) GetPlaceInfoByURL(placeURL string) (*Place, error) {
+  return getPlace(c, "flickr.places.getInfoByUrl", map[string]string{"url": placeURL})
+}
+
+// Returns the places inside a place that have public photos.
+func (c *Client) GetPlaceChildrenWithPhotos(placeID, woeID string) ([]Place, error) {
+  return getPlaces(c, "flickr.places.getChildrenWithPhotosPublic",
+    placeArgs(nil, placeID, woeID))
+}
+
+// Returns the places of type placeType where the calling user has taken
+// photos.  args may contain woe_id, place_id, threshold and the upload and
+// taken date filters.
+func (c *Client) PlacesForUser(placeType PlaceTypeID, args map[string]string) ([]Place, error) {
+  return getPlaces(c, "flickr.places.placesForUser",
+    withArg(args, "place_type_id", strconv.Itoa(int(placeType))))
+}
+
+// Returns the places of type placeType inside a bounding box.
+func (c *Client) PlacesForBoundingBox(minLon, minLat, maxLon, maxLat float64,
+  placeType PlaceTypeID) ([]Place, error) {
+  bbox := []string{
+    formatDegrees(minLon), formatDegrees(minLat),
+    formatDegrees(maxLon), formatDegrees(maxLat),
+  }
+  args := map[string]string{
+    "bbox":          strings.Join(bbox, ","),
+    "place_type_id": strconv.Itoa(int(placeType)),
+  }
+  return getPlaces(c, "flickr.places.placesForBoundingBox", args)
+}
+
+// Returns the places of type placeType with the most public photos on a day.
+// args may contain date, woe_id and place_id.
+func (c *Client) GetTopPlaces(placeType PlaceTypeID, args map[string]string) ([]Place, error) {
+  return getPlaces(c, "flickr.places.getTopPlacesList",
+    withArg(args, "place_type_id", strconv.Itoa(int(placeType))))
+}
+
+// A tag used on photos taken in a place.
+type PlaceTag struct {
+  Count int    `xml:"count,attr"`
+  Text  string `xml:",chardata"`
+}
+
+// Returns the most used tags of public photos taken in a place.  args may
+// contain the upload and taken date filters.
+func (c *Client) GetTagsForPlace(placeID, woeID string,
+  args map[string]string) ([]PlaceTag, error) {
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+    Tags []PlaceTag  `xml:"tags>tag"`
+  }{}
+  u := makeURL(c, "flickr.places.tagsForPlace", placeArgs(args, placeID, woeID), true)
+  if err := flickrGet(c, u, &r); err != nil {
+    return nil, err
+  }
+  if r.Stat != "ok" {
+    return nil, r.Err.Err()
+  }
+  return r.Tags, nil
+}
@@ -11,9 +11,9 @@ import (
   "net/http"
   "net/textproto"
   "net/url"
-  "path/filepath"
   "regexp"
   "sort"
+  "strconv"
   "strings"
 )
 
@@ -55,6 +55,16 @@ func wrapErr(msg string, err error) error {
   return errors.New(msg + ": " + err.Error())
 }
 
+// Adds page and per_page arguments to args, unless they are zero.
+func setPage(args map[string]string, page, perPage int) {
+  if page > 0 {
+    args["page"] = strconv.Itoa(page)
+  }
+  if perPage > 0 {
+    args["per_page"] = strconv.Itoa(perPage)
+  }
+}
+
 // Returns an API signature for the given arguments.
 func sign(secret string, args map[string]string) string {
   ks := keys(args)
@@ -97,6 +107,43 @@ func makeURL(c *Client, method string, args map[string]string, authenticated boo
   return u
 }
 
+// Returns a POST request for the Flickr method with the specified arguments,
+// signed with c.secret and carrying c.AuthToken.
+func postRequest(c *Client, method string, args map[string]string) (*http.Request, error) {
+  a := clone(args)
+  a["method"] = method
+  a["api_key"] = c.apiKey
+  a["auth_token"] = c.AuthToken
+  a["api_sig"] = sign(c.secret, a)
+  body := strings.NewReader(queryValues(a).Encode())
+  req, err := http.NewRequest("POST", service+"/rest/", body)
+  if err != nil {
+    return nil, wrapErr("request creation failed", err)
+  }
+  req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+  return req, nil
+}
+
+// Calls a Flickr method that changes data with a signed POST request, for
+// methods whose response carries nothing but the status.
+func postOK(c *Client, method string, args map[string]string) error {
+  req, err := postRequest(c, method, args)
+  if err != nil {
+    return err
+  }
+  r := struct {
+    Stat string      `xml:"stat,attr"`
+    Err  flickrError `xml:"err"`
+  }{}
+  if err := flickrPost(c, req, &r); err != nil {
+    return err
+  }
+  if r.Stat != "ok" {
+    return r.Err.Err()
+  }
+  return nil
+}
+
 // Regular expressions for identifying non-JSON part of the JSONP response
 // returned by Flickr.
 var (
@@ -175,15 +222,7 @@ func escapeQuotes(s string) string {
   return s
 }
 
-var contentType = map[string]string{
-  ".jpg":  "image/jpeg",
-  ".jpeg": "image/jpeg",
-  ".jpe":  "image/jpeg",
-  ".gif":  "image/gif",
-  ".png":  "image/png",
-}
-
-func multipartWriter(w io.Writer, filename string, photo []byte,
+func multipartWriter(w io.Writer, filename, contentType string, photo []byte,
   args map[string]string) (*multipart.Writer, error) {
   mpw := multipart.NewWriter(w)
   for k, v := range args {
@@ -195,7 +234,7 @@ func multipartWriter(w io.Writer, filename string, photo []byte,
   h.Set("Content-Disposition",
     fmt.Sprintf(`form-data; name="photo"; filename="%s"`,
       escapeQuotes(filename)))
-  h.Set("Content-Type", contentType[strings.ToLower(filepath.Ext(filename))])
+  h.Set("Content-Type", contentType)
   w, cErr := mpw.CreatePart(h)
   if cErr != nil {
     return nil, wrapErr("form file creation failed ["+filename+"]", cErr)
@@ -211,6 +250,11 @@ func multipartWriter(w io.Writer, filename string, photo []byte,
 
 func uploadRequest(c *Client, filename string, photo []byte,
   args map[string]string) (*http.Request, error) {
+  ct, tErr := DetectContentType(filename, photo)
+  if tErr != nil {
+    return nil, tErr
+  }
+
   a := clone(args)
   a["api_key"] = c.apiKey
   a["auth_token"] = c.AuthToken
@@ -218,7 +262,7 @@ func uploadRequest(c *Client, filename string, photo []byte,
   a["api_sig"] = sign(c.secret, a)
 
   buf := bytes.NewBuffer(make([]byte, 0, len(photo)*2))
-  mpw, wErr := multipartWriter(buf, filename, photo, a)
+  mpw, wErr := multipartWriter(buf, filename, ct, photo, a)
   if wErr != nil {
     return nil, wrapErr("writer creation failed", wErr)
   }
@@ -230,3 +274,29 @@ func uploadRequest(c *Client, filename string, photo []byte,
   req.Header.Set("Content-Type", mpw.FormDataContentType())
   return req, nil
 }
+
+// Formats a list of tags as a single space separated argument, quoting tags
+// that contain whitespace.  Double quotes can't be escaped in Flickr's tag
+// syntax, so they are dropped.
+func joinTags(tags []string) string {
+  quoted := make([]string, 0, len(tags))
+  for _, t := range tags {
+    t = strings.TrimSpace(strings.Replace(t, `"`, "", -1))
+    if t == "" {
+      continue
+    }
+    if strings.ContainsAny(t, " \t\n") {
+      t = `"` + t + `"`
+    }
+    quoted = append(quoted, t)
+  }
+  return strings.Join(quoted, " ")
+}
+
+// Returns the Flickr representation of a boolean argument.
+func boolArg(b bool) string {
+  if b {
+    return "1"
+  }
+  return "0"
+}
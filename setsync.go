@@ -0,0 +1,182 @@
+package flickgo
+
+import (
+  "bytes"
+  "errors"
+  "fmt"
+  "strconv"
+)
+
+// Changes needed to make a photo set match its desired contents.
+type SetDiff struct {
+  // ID of the set; empty if it doesn't exist yet and this was a dry run.
+  SetID string
+  Title string
+
+  // Whether the set has to be created.
+  Create bool
+
+  Add    []string
+  Remove []string
+
+  // Whether the photos have to be reordered after adding and removing.
+  Reorder bool
+
+  // Desired photo IDs, in order.
+  Order []string
+}
+
+// Reports whether the set already matches.
+func (d *SetDiff) Empty() bool {
+  return !d.Create && len(d.Add) == 0 && len(d.Remove) == 0 && !d.Reorder
+}
+
+// Returns the diff in a human readable form, one change per line.
+func (d *SetDiff) String() string {
+  var b bytes.Buffer
+  id := d.SetID
+  if id == "" {
+    id = "new"
+  }
+  fmt.Fprintf(&b, "set %q (%s)\n", d.Title, id)
+  if d.Create {
+    fmt.Fprintf(&b, "  create with primary photo %s\n", d.Order[0])
+  }
+  for _, p := range d.Add {
+    fmt.Fprintf(&b, "  + %s\n", p)
+  }
+  for _, p := range d.Remove {
+    fmt.Fprintf(&b, "  - %s\n", p)
+  }
+  if d.Reorder {
+    fmt.Fprintf(&b, "  reorder %d photos\n", len(d.Order))
+  }
+  if d.Empty() {
+    b.WriteString("  up to date\n")
+  }
+  return b.String()
+}
+
+// Makes the photo set of userID titled title contain exactly photoIDs, in that
+// order, creating the set if there isn't one.  If dryRun is set, nothing is
+// changed and the returned diff shows what would be done.
+func (c *Client) SyncSet(userID, title string, photoIDs []string,
+  dryRun bool) (*SetDiff, error) {
+  d := &SetDiff{Title: title}
+  seen := make(map[string]bool)
+  for _, id := range photoIDs {
+    if !seen[id] {
+      seen[id] = true
+      d.Order = append(d.Order, id)
+    }
+  }
+  if len(d.Order) == 0 {
+    return nil, errors.New("a photo set can't be empty")
+  }
+
+  set, fErr := c.findSet(userID, title)
+  if fErr != nil {
+    return nil, wrapErr("listing sets failed", fErr)
+  }
+
+  if set == nil {
+    d.Create = true
+    d.Add = d.Order[1:]
+    if dryRun {
+      return d, nil
+    }
+    id, err := c.CreateSet(title, "", d.Order[0])
+    if err != nil {
+      return d, wrapErr("creating set failed", err)
+    }
+    d.SetID = id
+    return d, c.applySetDiff(d, d.Order[0])
+  }
+
+  d.SetID = set.ID
+  var current []string
+  inSet := make(map[string]bool)
+  it := c.GetSetPhotosAll(set.ID, map[string]string{"per_page": "500"})
+  for it.Next() {
+    current = append(current, it.Photo().ID)
+    inSet[it.Photo().ID] = true
+  }
+  if err := it.Err(); err != nil {
+    return nil, wrapErr("listing set photos failed", err)
+  }
+
+  // Order of the set after adding and removing.
+  var after []string
+  for _, id := range current {
+    if seen[id] {
+      after = append(after, id)
+    } else {
+      d.Remove = append(d.Remove, id)
+    }
+  }
+  for _, id := range d.Order {
+    if !inSet[id] {
+      d.Add = append(d.Add, id)
+      after = append(after, id)
+    }
+  }
+  for i := range after {
+    if after[i] != d.Order[i] {
+      d.Reorder = true
+      break
+    }
+  }
+
+  if dryRun {
+    return d, nil
+  }
+  return d, c.applySetDiff(d, set.Primary)
+}
+
+// Applies d to an existing set whose primary photo is primary.
+func (c *Client) applySetDiff(d *SetDiff, primary string) error {
+  for _, id := range d.Add {
+    if err := c.AddPhotoToSet(id, d.SetID); err != nil {
+      return wrapErr("adding photo "+id+" failed", err)
+    }
+  }
+  for _, id := range d.Remove {
+    if id == primary {
+      // The primary photo must stay in the set; replace it first.
+      if err := c.SetPrimaryPhoto(d.SetID, d.Order[0]); err != nil {
+        return wrapErr("setting primary photo failed", err)
+      }
+      break
+    }
+  }
+  if len(d.Remove) > 0 {
+    if err := c.RemovePhotosFromSet(d.SetID, d.Remove); err != nil {
+      return wrapErr("removing photos failed", err)
+    }
+  }
+  if d.Reorder {
+    if err := c.ReorderSetPhotos(d.SetID, d.Order); err != nil {
+      return wrapErr("reordering photos failed", err)
+    }
+  }
+  return nil
+}
+
+// Returns the first set of userID titled title, or nil if there is none.
+func (c *Client) findSet(userID, title string) (*PhotoSet, error) {
+  for page := 1; ; page++ {
+    args := map[string]string{"page": strconv.Itoa(page), "per_page": "500"}
+    r, err := c.GetSetList(userID, args)
+    if err != nil {
+      return nil, err
+    }
+    for i := range r.Sets {
+      if r.Sets[i].Title == title {
+        return &r.Sets[i], nil
+      }
+    }
+    if page >= r.Pages {
+      return nil, nil
+    }
+  }
+}
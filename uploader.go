@@ -0,0 +1,447 @@
+package flickgo
+
+import (
+  "bufio"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "io/ioutil"
+  "os"
+  "path/filepath"
+  "sync"
+  "time"
+)
+
+// Default settings used by NewUploader.
+const (
+  DefaultUploadWorkers      = 4
+  DefaultUploadRetries      = 2
+  DefaultUploadRetryDelay   = 5 * time.Second
+  DefaultUploadPollInterval = 2 * time.Second
+  DefaultUploadPollTimeout  = 10 * time.Minute
+)
+
+// An action run against a photo once its upload has completed and its ID is
+// known.
+type UploadAction func(c *Client, photoID string) error
+
+// Flickr error code of flickr.photosets.addPhoto for a photo already in the
+// set.
+const setErrAlreadyIn = "3"
+
+// Returns an UploadAction that adds the uploaded photo to the photoset setID.
+// A photo that is already in the set, as when the action is run again after
+// its response was lost, is not an error.
+func AddToSetAction(setID string) UploadAction {
+  return func(c *Client, photoID string) error {
+    err := c.AddPhotoToSet(photoID, setID)
+    if e, ok := err.(*Error); ok && e.Code == setErrAlreadyIn {
+      return nil
+    }
+    return err
+  }
+}
+
+// A file to be uploaded by an Uploader.
+type UploadJob struct {
+  // Path of the file on the local filesystem.  Also used as the job's key in
+  // the journal, so it must be unique within a run.
+  Path string
+
+  // Extra upload arguments like title and description.  See
+  // http://www.flickr.com/services/api/upload.api.html.
+  Args map[string]string
+
+  // Tags to attach to the photo.
+  Tags []string
+
+  // Visibility of the photo.  Account defaults apply when nil.
+  Perms *Visibility
+
+  // Actions to run after the photo is uploaded, in order.
+  Actions []UploadAction
+}
+
+// Returns the upload arguments for j.
+func (j *UploadJob) args() map[string]string {
+  a := clone(j.Args)
+  if len(j.Tags) > 0 {
+    a["tags"] = joinTags(j.Tags)
+  }
+  if j.Perms != nil {
+    a["is_public"] = boolArg(j.Perms.IsPublic)
+    a["is_friend"] = boolArg(j.Perms.IsFriend)
+    a["is_family"] = boolArg(j.Perms.IsFamily)
+  }
+  return a
+}
+
+// Outcome of a single UploadJob.
+type UploadResult struct {
+  Path     string
+  TicketID string
+  PhotoID  string
+
+  // Number of upload attempts made in this run.
+  Attempts int
+
+  // Set when the journal shows the job was completed by an earlier run.
+  Skipped bool
+
+  // Non-nil if the job failed.
+  Err error
+}
+
+// Summary of an Uploader run.
+type UploadReport struct {
+  // One result per job, in the order the jobs were given.
+  Results []UploadResult
+
+  Uploaded int
+  Skipped  int
+  Failed   int
+}
+
+// Returns the results of the failed jobs.
+func (r *UploadReport) Failures() []UploadResult {
+  var f []UploadResult
+  for _, res := range r.Results {
+    if res.Err != nil {
+      f = append(f, res)
+    }
+  }
+  return f
+}
+
+func (r *UploadReport) String() string {
+  return fmt.Sprintf("%d uploaded, %d skipped, %d failed",
+    r.Uploaded, r.Skipped, r.Failed)
+}
+
+// Uploads many files concurrently using Client.Upload, waiting for each
+// ticket to complete with Client.CheckTickets.  When JournalPath is set,
+// progress is recorded there so that an interrupted run can be resumed
+// without uploading the same file twice.
+type Uploader struct {
+  Client *Client
+
+  // Number of files uploaded in parallel.
+  Workers int
+
+  // Number of times a failed upload, ticket check or action is retried.
+  // Files that can't be read or that Flickr won't take are not retried.
+  Retries int
+
+  // Delay before retrying.
+  RetryDelay time.Duration
+
+  // How often, and for how long, to poll for upload ticket completion.
+  PollInterval time.Duration
+  PollTimeout  time.Duration
+
+  // File used to persist progress across runs.  Optional.
+  JournalPath string
+}
+
+// Creates a new Uploader for c with default settings.
+func NewUploader(c *Client) *Uploader {
+  return &Uploader{
+    Client:       c,
+    Workers:      DefaultUploadWorkers,
+    Retries:      DefaultUploadRetries,
+    RetryDelay:   DefaultUploadRetryDelay,
+    PollInterval: DefaultUploadPollInterval,
+    PollTimeout:  DefaultUploadPollTimeout,
+  }
+}
+
+// Uploads all jobs and returns a report.  Failure of individual jobs is
+// reported in the returned UploadReport; error is non-nil only if the journal
+// could not be read or written.
+func (u *Uploader) Run(jobs []UploadJob) (*UploadReport, error) {
+  j, jErr := openJournal(u.JournalPath)
+  if jErr != nil {
+    return nil, jErr
+  }
+  defer j.close()
+
+  workers := u.Workers
+  if workers < 1 {
+    workers = 1
+  }
+  report := &UploadReport{Results: make([]UploadResult, len(jobs))}
+  idx := make(chan int)
+  var wg sync.WaitGroup
+  for w := 0; w < workers; w++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      for i := range idx {
+        report.Results[i] = u.process(j, &jobs[i])
+      }
+    }()
+  }
+  for i := range jobs {
+    idx <- i
+  }
+  close(idx)
+  wg.Wait()
+
+  for _, r := range report.Results {
+    switch {
+    case r.Err != nil:
+      report.Failed++
+    case r.Skipped:
+      report.Skipped++
+    default:
+      report.Uploaded++
+    }
+  }
+  return report, j.err()
+}
+
+// Uploads a single file and runs its actions, resuming from the journal
+// state if available.
+func (u *Uploader) process(j *journal, job *UploadJob) UploadResult {
+  res := UploadResult{Path: job.Path}
+  e := j.get(job.Path)
+  if e.Done {
+    res.TicketID, res.PhotoID, res.Skipped = e.TicketID, e.PhotoID, true
+    return res
+  }
+
+  res.PhotoID = e.PhotoID
+  for try := 0; res.PhotoID == ""; try++ {
+    if try > 0 {
+      if try > u.Retries {
+        return res
+      }
+      time.Sleep(u.RetryDelay)
+    }
+    if e.TicketID == "" {
+      res.Attempts++
+      ticket, retry, err := u.upload(job)
+      if err != nil {
+        res.Err = err
+        if !retry {
+          return res
+        }
+        continue
+      }
+      e.TicketID = ticket
+      j.put(job.Path, e)
+    }
+    res.TicketID = e.TicketID
+    photoID, dead, err := u.wait(e.TicketID)
+    if err != nil {
+      res.Err = err
+      if dead {
+        // Flickr rejected the upload; upload again.
+        e.TicketID = ""
+        j.put(job.Path, e)
+      }
+      // Otherwise the ticket may still complete, so it is polled again and
+      // kept in the journal for the next run.
+      continue
+    }
+    res.PhotoID, res.Err = photoID, nil
+    e.PhotoID = photoID
+    j.put(job.Path, e)
+  }
+
+  // Actions completed by an earlier run are not run again.
+  for i := e.Actions; i < len(job.Actions); i++ {
+    action := job.Actions[i]
+    err := u.retry(func() error { return action(u.Client, res.PhotoID) })
+    if err != nil {
+      res.Err = wrapErr("post-upload action failed", err)
+      return res
+    }
+    e.Actions = i + 1
+    j.put(job.Path, e)
+  }
+  e.Done = true
+  j.put(job.Path, e)
+  return res
+}
+
+// Reads the job's file and initiates its upload.  retry is set if the upload
+// failed in a way that may not happen again.
+func (u *Uploader) upload(job *UploadJob) (ticketID string, retry bool, err error) {
+  photo, rErr := ioutil.ReadFile(job.Path)
+  if rErr != nil {
+    return "", false, wrapErr("reading file failed", rErr)
+  }
+  if u.Client.Logger != nil {
+    u.Client.Logger.Debug("uploading %s\n", job.Path)
+  }
+  ticketID, err = u.Client.Upload(filepath.Base(job.Path), photo, job.args())
+  return ticketID, err != nil && retryable(err), err
+}
+
+// Calls f until it succeeds, fails with an error that isn't retryable, or
+// has been retried u.Retries times.
+func (u *Uploader) retry(f func() error) error {
+  for try := 0; ; try++ {
+    err := f()
+    if err == nil || try >= u.Retries || !retryable(err) {
+      return err
+    }
+    time.Sleep(u.RetryDelay)
+  }
+}
+
+// Flickr error code for a temporary outage.
+const errServiceUnavailable = "105"
+
+// Reports whether a request that failed with err may succeed when sent
+// again.  Only transport errors and Flickr outages are retried; files Flickr
+// won't take and other errors reported by Flickr are final.
+func retryable(err error) bool {
+  switch e := err.(type) {
+  case *UnsupportedFormatError, *UploadLimitError:
+    return false
+  case *Error:
+    return e.Code == errServiceUnavailable
+  }
+  return true
+}
+
+// Polls the upload ticket until it completes and returns the photo ID.  dead
+// is set if Flickr reports the ticket as invalid or the upload as failed, as
+// opposed to the ticket not completing in time or the poll failing.
+func (u *Uploader) wait(ticketID string) (photoID string, dead bool, err error) {
+  deadline := time.Now().Add(u.PollTimeout)
+  for {
+    statuses, cErr := u.Client.CheckTickets([]string{ticketID})
+    if cErr != nil {
+      return "", false, wrapErr("checking ticket "+ticketID+" failed", cErr)
+    }
+    for _, s := range statuses {
+      if s.ID != ticketID {
+        continue
+      }
+      if s.Invalid == "1" {
+        return "", true, errors.New("ticket " + ticketID + " is invalid")
+      }
+      switch s.Complete {
+      case "1":
+        return s.PhotoID, false, nil
+      case "2":
+        return "", true, errors.New("upload of ticket " + ticketID + " failed")
+      }
+    }
+    if time.Now().After(deadline) {
+      return "", false, errors.New("timed out waiting for ticket " + ticketID)
+    }
+    time.Sleep(u.PollInterval)
+  }
+}
+
+// Progress of a single job as recorded in the journal.
+type journalEntry struct {
+  Path     string `json:"path"`
+  TicketID string `json:"ticket_id,omitempty"`
+  PhotoID  string `json:"photo_id,omitempty"`
+
+  // Number of the job's actions completed, in order.
+  Actions int  `json:"actions,omitempty"`
+  Done    bool `json:"done,omitempty"`
+}
+
+// Append-only record of upload progress, one JSON object per line.  Later
+// lines for a path override earlier ones.
+type journal struct {
+  mu      sync.Mutex
+  f       *os.File
+  entries map[string]journalEntry
+  wErr    error
+}
+
+// Opens the journal at path, loading existing entries.  An empty path yields
+// an in-memory journal.
+func openJournal(path string) (*journal, error) {
+  j := &journal{entries: make(map[string]journalEntry)}
+  if path == "" {
+    return j, nil
+  }
+  f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+  if err != nil {
+    return nil, wrapErr("opening journal failed", err)
+  }
+  r := bufio.NewReader(f)
+  var size int64
+  for {
+    line, rErr := r.ReadBytes('\n')
+    if rErr != nil && rErr != io.EOF {
+      f.Close()
+      return nil, wrapErr("reading journal failed", rErr)
+    }
+    var e journalEntry
+    ok := json.Unmarshal(line, &e) == nil
+    if ok {
+      j.entries[e.Path] = e
+    }
+    if rErr == nil {
+      size += int64(len(line))
+      continue
+    }
+    // The last line of a run interrupted while writing it lacks its newline.
+    // Complete it if it is whole, or cut it off, so that the next entry
+    // starts on a line of its own.
+    if ok {
+      _, err = f.Write([]byte{'\n'})
+    } else if len(line) > 0 {
+      err = f.Truncate(size)
+    }
+    if err != nil {
+      f.Close()
+      return nil, wrapErr("repairing journal failed", err)
+    }
+    break
+  }
+  j.f = f
+  return j, nil
+}
+
+func (j *journal) get(path string) journalEntry {
+  j.mu.Lock()
+  defer j.mu.Unlock()
+  e := j.entries[path]
+  e.Path = path
+  return e
+}
+
+func (j *journal) put(path string, e journalEntry) {
+  j.mu.Lock()
+  defer j.mu.Unlock()
+  e.Path = path
+  j.entries[path] = e
+  if j.f == nil || j.wErr != nil {
+    return
+  }
+  b, err := json.Marshal(e)
+  if err == nil {
+    _, err = j.f.Write(append(b, '\n'))
+  }
+  if err == nil {
+    err = j.f.Sync()
+  }
+  if err != nil {
+    j.wErr = wrapErr("writing journal failed", err)
+  }
+}
+
+// Returns the first write error, if any.
+func (j *journal) err() error {
+  j.mu.Lock()
+  defer j.mu.Unlock()
+  return j.wErr
+}
+
+func (j *journal) close() {
+  if j.f != nil {
+    j.f.Close()
+  }
+}